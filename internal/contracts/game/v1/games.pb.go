@@ -24,6 +24,7 @@ const (
 type CreateGameRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserIds       []string               `protobuf:"bytes,1,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Settings      *GameSettings          `protobuf:"bytes,2,opt,name=settings,proto3" json:"settings,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,6 +66,73 @@ func (x *CreateGameRequest) GetUserIds() []string {
 	return nil
 }
 
+func (x *CreateGameRequest) GetSettings() *GameSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type GameSettings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	DeckSize      int32                  `protobuf:"varint,2,opt,name=deck_size,json=deckSize,proto3" json:"deck_size,omitempty"`
+	Ranked        bool                   `protobuf:"varint,3,opt,name=ranked,proto3" json:"ranked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameSettings) Reset() {
+	*x = GameSettings{}
+	mi := &file_game_v1_games_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameSettings) ProtoMessage() {}
+
+func (x *GameSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_games_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameSettings.ProtoReflect.Descriptor instead.
+func (*GameSettings) Descriptor() ([]byte, []int) {
+	return file_game_v1_games_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GameSettings) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *GameSettings) GetDeckSize() int32 {
+	if x != nil {
+		return x.DeckSize
+	}
+	return 0
+}
+
+func (x *GameSettings) GetRanked() bool {
+	if x != nil {
+		return x.Ranked
+	}
+	return false
+}
+
 type CreateGameResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
@@ -74,7 +142,7 @@ type CreateGameResponse struct {
 
 func (x *CreateGameResponse) Reset() {
 	*x = CreateGameResponse{}
-	mi := &file_game_v1_games_proto_msgTypes[1]
+	mi := &file_game_v1_games_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -86,7 +154,7 @@ func (x *CreateGameResponse) String() string {
 func (*CreateGameResponse) ProtoMessage() {}
 
 func (x *CreateGameResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_games_proto_msgTypes[1]
+	mi := &file_game_v1_games_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -99,7 +167,7 @@ func (x *CreateGameResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateGameResponse.ProtoReflect.Descriptor instead.
 func (*CreateGameResponse) Descriptor() ([]byte, []int) {
-	return file_game_v1_games_proto_rawDescGZIP(), []int{1}
+	return file_game_v1_games_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CreateGameResponse) GetGameId() string {
@@ -113,9 +181,14 @@ var File_game_v1_games_proto protoreflect.FileDescriptor
 
 const file_game_v1_games_proto_rawDesc = "" +
 	"\n" +
-	"\x13game/v1/games.proto\".\n" +
+	"\x13game/v1/games.proto\"Y\n" +
 	"\x11CreateGameRequest\x12\x19\n" +
-	"\buser_ids\x18\x01 \x03(\tR\auserIds\"-\n" +
+	"\buser_ids\x18\x01 \x03(\tR\auserIds\x12)\n" +
+	"\bsettings\x18\x02 \x01(\v2\r.GameSettingsR\bsettings\"W\n" +
+	"\fGameSettings\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x1b\n" +
+	"\tdeck_size\x18\x02 \x01(\x05R\bdeckSize\x12\x16\n" +
+	"\x06ranked\x18\x03 \x01(\bR\x06ranked\"-\n" +
 	"\x12CreateGameResponse\x12\x17\n" +
 	"\agame_id\x18\x01 \x01(\tR\x06gameId2?\n" +
 	"\x04Game\x127\n" +
@@ -134,19 +207,21 @@ func file_game_v1_games_proto_rawDescGZIP() []byte {
 	return file_game_v1_games_proto_rawDescData
 }
 
-var file_game_v1_games_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_game_v1_games_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_game_v1_games_proto_goTypes = []any{
 	(*CreateGameRequest)(nil),  // 0: CreateGameRequest
-	(*CreateGameResponse)(nil), // 1: CreateGameResponse
+	(*GameSettings)(nil),       // 1: GameSettings
+	(*CreateGameResponse)(nil), // 2: CreateGameResponse
 }
 var file_game_v1_games_proto_depIdxs = []int32{
-	0, // 0: Game.CreateGame:input_type -> CreateGameRequest
-	1, // 1: Game.CreateGame:output_type -> CreateGameResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: CreateGameRequest.settings:type_name -> GameSettings
+	0, // 1: Game.CreateGame:input_type -> CreateGameRequest
+	2, // 2: Game.CreateGame:output_type -> CreateGameResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_game_v1_games_proto_init() }
@@ -160,7 +235,7 @@ func file_game_v1_games_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_v1_games_proto_rawDesc), len(file_game_v1_games_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
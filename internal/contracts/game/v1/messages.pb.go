@@ -0,0 +1,1189 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: game/v1/messages.proto
+
+package game
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Card struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suit          int32                  `protobuf:"varint,1,opt,name=suit,proto3" json:"suit,omitempty"`
+	Rank          int32                  `protobuf:"varint,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	mi := &file_game_v1_messages_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Card) GetSuit() int32 {
+	if x != nil {
+		return x.Suit
+	}
+	return 0
+}
+
+func (x *Card) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+type TableCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suit          int32                  `protobuf:"varint,1,opt,name=suit,proto3" json:"suit,omitempty"`
+	Rank          int32                  `protobuf:"varint,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	BeatOff       *Card                  `protobuf:"bytes,3,opt,name=beat_off,json=beatOff,proto3" json:"beat_off,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TableCard) Reset() {
+	*x = TableCard{}
+	mi := &file_game_v1_messages_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableCard) ProtoMessage() {}
+
+func (x *TableCard) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableCard.ProtoReflect.Descriptor instead.
+func (*TableCard) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TableCard) GetSuit() int32 {
+	if x != nil {
+		return x.Suit
+	}
+	return 0
+}
+
+func (x *TableCard) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *TableCard) GetBeatOff() *Card {
+	if x != nil {
+		return x.BeatOff
+	}
+	return nil
+}
+
+type Me struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Place         int32                  `protobuf:"varint,2,opt,name=place,proto3" json:"place,omitempty"`
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Cards         []*Card                `protobuf:"bytes,5,rep,name=cards,proto3" json:"cards,omitempty"`
+	TakenCards    []*Card                `protobuf:"bytes,6,rep,name=taken_cards,json=takenCards,proto3" json:"taken_cards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Me) Reset() {
+	*x = Me{}
+	mi := &file_game_v1_messages_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Me) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Me) ProtoMessage() {}
+
+func (x *Me) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Me.ProtoReflect.Descriptor instead.
+func (*Me) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Me) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Me) GetPlace() int32 {
+	if x != nil {
+		return x.Place
+	}
+	return 0
+}
+
+func (x *Me) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Me) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Me) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *Me) GetTakenCards() []*Card {
+	if x != nil {
+		return x.TakenCards
+	}
+	return nil
+}
+
+type UserState struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status           int32                  `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	Name             string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CardLength       int32                  `protobuf:"varint,4,opt,name=card_length,json=cardLength,proto3" json:"card_length,omitempty"`
+	TakenCardsLength int32                  `protobuf:"varint,5,opt,name=taken_cards_length,json=takenCardsLength,proto3" json:"taken_cards_length,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UserState) Reset() {
+	*x = UserState{}
+	mi := &file_game_v1_messages_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserState) ProtoMessage() {}
+
+func (x *UserState) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserState.ProtoReflect.Descriptor instead.
+func (*UserState) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UserState) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserState) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *UserState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UserState) GetCardLength() int32 {
+	if x != nil {
+		return x.CardLength
+	}
+	return 0
+}
+
+func (x *UserState) GetTakenCardsLength() int32 {
+	if x != nil {
+		return x.TakenCardsLength
+	}
+	return 0
+}
+
+type GameStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Me            *Me                    `protobuf:"bytes,1,opt,name=me,proto3" json:"me,omitempty"`
+	Users         []*UserState           `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty"`
+	AttackingId   string                 `protobuf:"bytes,3,opt,name=attacking_id,json=attackingId,proto3" json:"attacking_id,omitempty"`
+	DefendingId   string                 `protobuf:"bytes,4,opt,name=defending_id,json=defendingId,proto3" json:"defending_id,omitempty"`
+	DeckLength    int32                  `protobuf:"varint,5,opt,name=deck_length,json=deckLength,proto3" json:"deck_length,omitempty"`
+	TrumpSuit     int32                  `protobuf:"varint,6,opt,name=trump_suit,json=trumpSuit,proto3" json:"trump_suit,omitempty"`
+	TableCards    []*TableCard           `protobuf:"bytes,7,rep,name=table_cards,json=tableCards,proto3" json:"table_cards,omitempty"`
+	EventSeq      int64                  `protobuf:"varint,8,opt,name=event_seq,json=eventSeq,proto3" json:"event_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameStateResponse) Reset() {
+	*x = GameStateResponse{}
+	mi := &file_game_v1_messages_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameStateResponse) ProtoMessage() {}
+
+func (x *GameStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameStateResponse.ProtoReflect.Descriptor instead.
+func (*GameStateResponse) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GameStateResponse) GetMe() *Me {
+	if x != nil {
+		return x.Me
+	}
+	return nil
+}
+
+func (x *GameStateResponse) GetUsers() []*UserState {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *GameStateResponse) GetAttackingId() string {
+	if x != nil {
+		return x.AttackingId
+	}
+	return ""
+}
+
+func (x *GameStateResponse) GetDefendingId() string {
+	if x != nil {
+		return x.DefendingId
+	}
+	return ""
+}
+
+func (x *GameStateResponse) GetDeckLength() int32 {
+	if x != nil {
+		return x.DeckLength
+	}
+	return 0
+}
+
+func (x *GameStateResponse) GetTrumpSuit() int32 {
+	if x != nil {
+		return x.TrumpSuit
+	}
+	return 0
+}
+
+func (x *GameStateResponse) GetTableCards() []*TableCard {
+	if x != nil {
+		return x.TableCards
+	}
+	return nil
+}
+
+func (x *GameStateResponse) GetEventSeq() int64 {
+	if x != nil {
+		return x.EventSeq
+	}
+	return 0
+}
+
+type Command struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Card          *Card                  `protobuf:"bytes,4,opt,name=card,proto3" json:"card,omitempty"`
+	TargetCard    *Card                  `protobuf:"bytes,5,opt,name=target_card,json=targetCard,proto3" json:"target_card,omitempty"`
+	UserCard      *Card                  `protobuf:"bytes,6,opt,name=user_card,json=userCard,proto3" json:"user_card,omitempty"`
+	CommandId     string                 `protobuf:"bytes,7,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Text          string                 `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
+	Emote         string                 `protobuf:"bytes,9,opt,name=emote,proto3" json:"emote,omitempty"`
+	TargetId      string                 `protobuf:"bytes,10,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_game_v1_messages_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Command) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *Command) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Command) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Command) GetCard() *Card {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *Command) GetTargetCard() *Card {
+	if x != nil {
+		return x.TargetCard
+	}
+	return nil
+}
+
+func (x *Command) GetUserCard() *Card {
+	if x != nil {
+		return x.UserCard
+	}
+	return nil
+}
+
+func (x *Command) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *Command) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Command) GetEmote() string {
+	if x != nil {
+		return x.Emote
+	}
+	return ""
+}
+
+func (x *Command) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+type CommandResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Command       *Command               `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	State         *GameStateResponse     `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandResponse) Reset() {
+	*x = CommandResponse{}
+	mi := &file_game_v1_messages_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandResponse) ProtoMessage() {}
+
+func (x *CommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
+func (*CommandResponse) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CommandResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CommandResponse) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *CommandResponse) GetState() *GameStateResponse {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type GameEvent struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Event            string                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	UserId           string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Card             *Card                  `protobuf:"bytes,3,opt,name=card,proto3" json:"card,omitempty"`
+	AttackerId       string                 `protobuf:"bytes,4,opt,name=attacker_id,json=attackerId,proto3" json:"attacker_id,omitempty"`
+	TargetCard       *Card                  `protobuf:"bytes,5,opt,name=target_card,json=targetCard,proto3" json:"target_card,omitempty"`
+	UserCard         *Card                  `protobuf:"bytes,6,opt,name=user_card,json=userCard,proto3" json:"user_card,omitempty"`
+	DefenderId       string                 `protobuf:"bytes,7,opt,name=defender_id,json=defenderId,proto3" json:"defender_id,omitempty"`
+	Completed        bool                   `protobuf:"varint,8,opt,name=completed,proto3" json:"completed,omitempty"`
+	TimerEndAtUnixMs int64                  `protobuf:"varint,9,opt,name=timer_end_at_unix_ms,json=timerEndAtUnixMs,proto3" json:"timer_end_at_unix_ms,omitempty"`
+	GameResult       string                 `protobuf:"bytes,10,opt,name=game_result,json=gameResult,proto3" json:"game_result,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GameEvent) Reset() {
+	*x = GameEvent{}
+	mi := &file_game_v1_messages_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameEvent) ProtoMessage() {}
+
+func (x *GameEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameEvent.ProtoReflect.Descriptor instead.
+func (*GameEvent) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GameEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *GameEvent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GameEvent) GetCard() *Card {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *GameEvent) GetAttackerId() string {
+	if x != nil {
+		return x.AttackerId
+	}
+	return ""
+}
+
+func (x *GameEvent) GetTargetCard() *Card {
+	if x != nil {
+		return x.TargetCard
+	}
+	return nil
+}
+
+func (x *GameEvent) GetUserCard() *Card {
+	if x != nil {
+		return x.UserCard
+	}
+	return nil
+}
+
+func (x *GameEvent) GetDefenderId() string {
+	if x != nil {
+		return x.DefenderId
+	}
+	return ""
+}
+
+func (x *GameEvent) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *GameEvent) GetTimerEndAtUnixMs() int64 {
+	if x != nil {
+		return x.TimerEndAtUnixMs
+	}
+	return 0
+}
+
+func (x *GameEvent) GetGameResult() string {
+	if x != nil {
+		return x.GameResult
+	}
+	return ""
+}
+
+type ChatEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         string                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Emote         string                 `protobuf:"bytes,4,opt,name=emote,proto3" json:"emote,omitempty"`
+	SentAtUnixMs  int64                  `protobuf:"varint,5,opt,name=sent_at_unix_ms,json=sentAtUnixMs,proto3" json:"sent_at_unix_ms,omitempty"`
+	Action        string                 `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatEvent) Reset() {
+	*x = ChatEvent{}
+	mi := &file_game_v1_messages_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatEvent) ProtoMessage() {}
+
+func (x *ChatEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatEvent.ProtoReflect.Descriptor instead.
+func (*ChatEvent) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ChatEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetEmote() string {
+	if x != nil {
+		return x.Emote
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetSentAtUnixMs() int64 {
+	if x != nil {
+		return x.SentAtUnixMs
+	}
+	return 0
+}
+
+func (x *ChatEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CommandRejected struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         string                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandRejected) Reset() {
+	*x = CommandRejected{}
+	mi := &file_game_v1_messages_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRejected) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRejected) ProtoMessage() {}
+
+func (x *CommandRejected) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRejected.ProtoReflect.Descriptor instead.
+func (*CommandRejected) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CommandRejected) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *CommandRejected) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *CommandRejected) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Message struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Message_Response
+	//	*Message_Event
+	//	*Message_Chat
+	//	*Message_Rejected
+	Payload       isMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_game_v1_messages_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Message) GetPayload() isMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Message) GetResponse() *CommandResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Response); ok {
+			return x.Response
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetEvent() *GameEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetChat() *ChatEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Chat); ok {
+			return x.Chat
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetRejected() *CommandRejected {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Rejected); ok {
+			return x.Rejected
+		}
+	}
+	return nil
+}
+
+type isMessage_Payload interface {
+	isMessage_Payload()
+}
+
+type Message_Response struct {
+	Response *CommandResponse `protobuf:"bytes,1,opt,name=response,proto3,oneof"`
+}
+
+type Message_Event struct {
+	Event *GameEvent `protobuf:"bytes,2,opt,name=event,proto3,oneof"`
+}
+
+type Message_Chat struct {
+	Chat *ChatEvent `protobuf:"bytes,3,opt,name=chat,proto3,oneof"`
+}
+
+type Message_Rejected struct {
+	Rejected *CommandRejected `protobuf:"bytes,4,opt,name=rejected,proto3,oneof"`
+}
+
+func (*Message_Response) isMessage_Payload() {}
+
+func (*Message_Event) isMessage_Payload() {}
+
+func (*Message_Chat) isMessage_Payload() {}
+
+func (*Message_Rejected) isMessage_Payload() {}
+
+type MessagePack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	GameState     *GameStateResponse     `protobuf:"bytes,2,opt,name=game_state,json=gameState,proto3" json:"game_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessagePack) Reset() {
+	*x = MessagePack{}
+	mi := &file_game_v1_messages_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessagePack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessagePack) ProtoMessage() {}
+
+func (x *MessagePack) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_messages_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessagePack.ProtoReflect.Descriptor instead.
+func (*MessagePack) Descriptor() ([]byte, []int) {
+	return file_game_v1_messages_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MessagePack) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *MessagePack) GetGameState() *GameStateResponse {
+	if x != nil {
+		return x.GameState
+	}
+	return nil
+}
+
+var File_game_v1_messages_proto protoreflect.FileDescriptor
+
+const file_game_v1_messages_proto_rawDesc = "" +
+	"\n" +
+	"\x16game/v1/messages.proto\".\n" +
+	"\x04Card\x12\x12\n" +
+	"\x04suit\x18\x01 \x01(\x05R\x04suit\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x05R\x04rank\"U\n" +
+	"\tTableCard\x12\x12\n" +
+	"\x04suit\x18\x01 \x01(\x05R\x04suit\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x05R\x04rank\x12 \n" +
+	"\bbeat_off\x18\x03 \x01(\v2\x05.CardR\abeatOff\"\x9b\x01\n" +
+	"\x02Me\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05place\x18\x02 \x01(\x05R\x05place\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1b\n" +
+	"\x05cards\x18\x05 \x03(\v2\x05.CardR\x05cards\x12&\n" +
+	"\vtaken_cards\x18\x06 \x03(\v2\x05.CardR\n" +
+	"takenCards\"\x96\x01\n" +
+	"\tUserState\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x05R\x06status\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1f\n" +
+	"\vcard_length\x18\x04 \x01(\x05R\n" +
+	"cardLength\x12,\n" +
+	"\x12taken_cards_length\x18\x05 \x01(\x05R\x10takenCardsLength\"\x9a\x02\n" +
+	"\x11GameStateResponse\x12\x13\n" +
+	"\x02me\x18\x01 \x01(\v2\x03.MeR\x02me\x12 \n" +
+	"\x05users\x18\x02 \x03(\v2\n" +
+	".UserStateR\x05users\x12!\n" +
+	"\fattacking_id\x18\x03 \x01(\tR\vattackingId\x12!\n" +
+	"\fdefending_id\x18\x04 \x01(\tR\vdefendingId\x12\x1f\n" +
+	"\vdeck_length\x18\x05 \x01(\x05R\n" +
+	"deckLength\x12\x1d\n" +
+	"\n" +
+	"trump_suit\x18\x06 \x01(\x05R\ttrumpSuit\x12+\n" +
+	"\vtable_cards\x18\a \x03(\v2\n" +
+	".TableCardR\n" +
+	"tableCards\x12\x1b\n" +
+	"\tevent_seq\x18\b \x01(\x03R\beventSeq\"\xa0\x02\n" +
+	"\aCommand\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x19\n" +
+	"\x04card\x18\x04 \x01(\v2\x05.CardR\x04card\x12&\n" +
+	"\vtarget_card\x18\x05 \x01(\v2\x05.CardR\n" +
+	"targetCard\x12\"\n" +
+	"\tuser_card\x18\x06 \x01(\v2\x05.CardR\buserCard\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\a \x01(\tR\tcommandId\x12\x12\n" +
+	"\x04text\x18\b \x01(\tR\x04text\x12\x14\n" +
+	"\x05emote\x18\t \x01(\tR\x05emote\x12\x1b\n" +
+	"\ttarget_id\x18\n" +
+	" \x01(\tR\btargetId\"u\n" +
+	"\x0fCommandResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\"\n" +
+	"\acommand\x18\x02 \x01(\v2\b.CommandR\acommand\x12(\n" +
+	"\x05state\x18\x03 \x01(\v2\x12.GameStateResponseR\x05state\"\xd2\x02\n" +
+	"\tGameEvent\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\x04card\x18\x03 \x01(\v2\x05.CardR\x04card\x12\x1f\n" +
+	"\vattacker_id\x18\x04 \x01(\tR\n" +
+	"attackerId\x12&\n" +
+	"\vtarget_card\x18\x05 \x01(\v2\x05.CardR\n" +
+	"targetCard\x12\"\n" +
+	"\tuser_card\x18\x06 \x01(\v2\x05.CardR\buserCard\x12\x1f\n" +
+	"\vdefender_id\x18\a \x01(\tR\n" +
+	"defenderId\x12\x1c\n" +
+	"\tcompleted\x18\b \x01(\bR\tcompleted\x12.\n" +
+	"\x14timer_end_at_unix_ms\x18\t \x01(\x03R\x10timerEndAtUnixMs\x12\x1f\n" +
+	"\vgame_result\x18\n" +
+	" \x01(\tR\n" +
+	"gameResult\"\xb9\x01\n" +
+	"\tChatEvent\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x14\n" +
+	"\x05emote\x18\x04 \x01(\tR\x05emote\x12%\n" +
+	"\x0fsent_at_unix_ms\x18\x05 \x01(\x03R\fsentAtUnixMs\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"U\n" +
+	"\x0fCommandRejected\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xba\x01\n" +
+	"\aMessage\x12.\n" +
+	"\bresponse\x18\x01 \x01(\v2\x10.CommandResponseH\x00R\bresponse\x12\"\n" +
+	"\x05event\x18\x02 \x01(\v2\n" +
+	".GameEventH\x00R\x05event\x12 \n" +
+	"\x04chat\x18\x03 \x01(\v2\n" +
+	".ChatEventH\x00R\x04chat\x12.\n" +
+	"\brejected\x18\x04 \x01(\v2\x10.CommandRejectedH\x00R\brejectedB\t\n" +
+	"\apayload\"f\n" +
+	"\vMessagePack\x12$\n" +
+	"\bmessages\x18\x01 \x03(\v2\b.MessageR\bmessages\x121\n" +
+	"\n" +
+	"game_state\x18\x02 \x01(\v2\x12.GameStateResponseR\tgameStateB\tZ\a./;gameb\x06proto3"
+
+var (
+	file_game_v1_messages_proto_rawDescOnce sync.Once
+	file_game_v1_messages_proto_rawDescData []byte
+)
+
+func file_game_v1_messages_proto_rawDescGZIP() []byte {
+	file_game_v1_messages_proto_rawDescOnce.Do(func() {
+		file_game_v1_messages_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_game_v1_messages_proto_rawDesc), len(file_game_v1_messages_proto_rawDesc)))
+	})
+	return file_game_v1_messages_proto_rawDescData
+}
+
+var file_game_v1_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_game_v1_messages_proto_goTypes = []any{
+	(*Card)(nil),              // 0: Card
+	(*TableCard)(nil),         // 1: TableCard
+	(*Me)(nil),                // 2: Me
+	(*UserState)(nil),         // 3: UserState
+	(*GameStateResponse)(nil), // 4: GameStateResponse
+	(*Command)(nil),           // 5: Command
+	(*CommandResponse)(nil),   // 6: CommandResponse
+	(*GameEvent)(nil),         // 7: GameEvent
+	(*ChatEvent)(nil),         // 8: ChatEvent
+	(*CommandRejected)(nil),   // 9: CommandRejected
+	(*Message)(nil),           // 10: Message
+	(*MessagePack)(nil),       // 11: MessagePack
+}
+var file_game_v1_messages_proto_depIdxs = []int32{
+	0,  // 0: TableCard.beat_off:type_name -> Card
+	0,  // 1: Me.cards:type_name -> Card
+	0,  // 2: Me.taken_cards:type_name -> Card
+	2,  // 3: GameStateResponse.me:type_name -> Me
+	3,  // 4: GameStateResponse.users:type_name -> UserState
+	1,  // 5: GameStateResponse.table_cards:type_name -> TableCard
+	0,  // 6: Command.card:type_name -> Card
+	0,  // 7: Command.target_card:type_name -> Card
+	0,  // 8: Command.user_card:type_name -> Card
+	5,  // 9: CommandResponse.command:type_name -> Command
+	4,  // 10: CommandResponse.state:type_name -> GameStateResponse
+	0,  // 11: GameEvent.card:type_name -> Card
+	0,  // 12: GameEvent.target_card:type_name -> Card
+	0,  // 13: GameEvent.user_card:type_name -> Card
+	6,  // 14: Message.response:type_name -> CommandResponse
+	7,  // 15: Message.event:type_name -> GameEvent
+	8,  // 16: Message.chat:type_name -> ChatEvent
+	9,  // 17: Message.rejected:type_name -> CommandRejected
+	10, // 18: MessagePack.messages:type_name -> Message
+	4,  // 19: MessagePack.game_state:type_name -> GameStateResponse
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_game_v1_messages_proto_init() }
+func file_game_v1_messages_proto_init() {
+	if File_game_v1_messages_proto != nil {
+		return
+	}
+	file_game_v1_messages_proto_msgTypes[10].OneofWrappers = []any{
+		(*Message_Response)(nil),
+		(*Message_Event)(nil),
+		(*Message_Chat)(nil),
+		(*Message_Rejected)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_v1_messages_proto_rawDesc), len(file_game_v1_messages_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_game_v1_messages_proto_goTypes,
+		DependencyIndexes: file_game_v1_messages_proto_depIdxs,
+		MessageInfos:      file_game_v1_messages_proto_msgTypes,
+	}.Build()
+	File_game_v1_messages_proto = out.File
+	file_game_v1_messages_proto_goTypes = nil
+	file_game_v1_messages_proto_depIdxs = nil
+}
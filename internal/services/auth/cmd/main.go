@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/core"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/delivery/http"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"github.com/MommusWinner/MicroDurak/lib/validate"
 	"github.com/go-playground/validator"
 	"github.com/swaggo/echo-swagger"
@@ -23,11 +27,19 @@ func main() {
 
 	di := core.NewDi()
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "auth", di.Ctx.Config().GetTracing())
+	if err != nil {
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
+	e.Use(tracing.Middleware("auth"))
+	e.Use(logging.Middleware(di.Ctx.Logger()))
+
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	http.AddRoutes(e, di.AuthHandler)
+	http.AddRoutes(e, di.AuthHandler, di.Ctx.Config().GetJwtPublic())
 
-	err := e.Start(":" + di.Ctx.Config().GetPort())
+	err = e.Start(":" + di.Ctx.Config().GetPort())
 	if err != nil {
 		panic(err)
 	}
@@ -3,27 +3,35 @@ package connection
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/database"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/repositories"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
 	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
 )
 
 type connection struct {
 	conn    *pgx.Conn
+	redis   *redis.Client
 	queries *database.Queries
 
 	authRepository repositories.AuthRepository
+	tickets        domain.Tickets
 }
 
-func makeConnection(conn *pgx.Conn) *connection {
+func makeConnection(conn *pgx.Conn, client *redis.Client, ticketTTL time.Duration) *connection {
 	queries := database.New(conn)
 
 	return &connection{
+		conn:           conn,
+		redis:          client,
 		queries:        queries,
 		authRepository: NewAuthRepository(queries),
+		tickets:        ticket.NewStore(client, ticketTTL),
 	}
 }
 
@@ -34,14 +42,24 @@ func Make(cfg infra.Config) domain.Connection {
 		panic(fmt.Sprintf("unable to open database due [%s]", err))
 	}
 
-	return makeConnection(conn)
+	opt, err := redis.ParseURL(cfg.GetRedisURL())
+	if err != nil {
+		panic(fmt.Sprintf("unable to parse redis URL due [%s]", err))
+	}
+
+	return makeConnection(conn, redis.NewClient(opt), cfg.GetTicketTTL())
 }
 
 func Close(conn domain.Connection) {
 	c := conn.(*connection)
 	c.conn.Close(context.TODO())
+	c.redis.Close()
 }
 
 func (c *connection) AuthRepository() repositories.AuthRepository {
 	return c.authRepository
 }
+
+func (c *connection) Tickets() domain.Tickets {
+	return c.tickets
+}
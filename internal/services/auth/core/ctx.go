@@ -2,12 +2,12 @@ package core
 
 import (
 	"log/slog"
-	"os"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/connection"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/infra/config"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
 )
 
 type Ctx struct {
@@ -37,7 +37,11 @@ func (c *Ctx) Make() domain.Context {
 
 func InitCtx() *Ctx {
 	cfg := config.Make()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger, err := logging.New("auth", cfg.GetLogLevel())
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
 
 	db := connection.Make(cfg)
 
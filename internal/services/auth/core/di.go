@@ -1,8 +1,6 @@
 package core
 
 import (
-	"fmt"
-
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/delivery/http"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/cases"
@@ -18,8 +16,6 @@ func NewDi() *Di {
 	playersClient := MakePlayersClient(ctx.Config())
 	smtp := MakeSMTP(ctx.Config())
 
-	ctx.Logger().Info(fmt.Sprint(smtp))
-
 	var (
 		authUseCase = cases.NewAuthUseCase(ctx, playersClient, smtp)
 		authHandler = http.NewAuthHandler(authUseCase)
@@ -97,6 +97,49 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/ticket": {
+            "post": {
+                "description": "Returns a short-lived single-use ticket for the matchmaker or for one game. Pass it as the ticket query parameter when opening the WebSocket instead of the JWT.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Issue a WebSocket ticket",
+                "parameters": [
+                    {
+                        "description": "Ticket scope",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.TicketRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Ticket issued",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.TicketResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -150,6 +193,35 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "delivery_http.TicketRequest": {
+            "type": "object",
+            "required": [
+                "scope"
+            ],
+            "properties": {
+                "game_id": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "matchmaker",
+                        "game"
+                    ]
+                }
+            }
+        },
+        "delivery_http.TicketResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer"
+                },
+                "ticket": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
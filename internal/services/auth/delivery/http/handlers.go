@@ -36,6 +36,16 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type TicketRequest struct {
+	Scope  string `json:"scope" validate:"required,oneof=matchmaker game"`
+	GameID string `json:"game_id"`
+}
+
+type TicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
 var internalServerError = echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
 
 // Register creates a new user account
@@ -59,7 +69,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return err
 	}
 
-	resp, err := h.useCase.Register(props.RegisterReq{Name: r.Name, Age: int(r.Age), Email: r.Email, Password: r.Password})
+	resp, err := h.useCase.Register(c.Request().Context(), props.RegisterReq{Name: r.Name, Age: int(r.Age), Email: r.Email, Password: r.Password})
 
 	if err != nil {
 		if errors.Is(err, cases.ErrEmailAlreadyTaken) {
@@ -92,7 +102,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "bad request")
 	}
 
-	resp, err := h.useCase.Login(props.LoginReq{Email: r.Email, Password: r.Password})
+	resp, err := h.useCase.Login(c.Request().Context(), props.LoginReq{Email: r.Email, Password: r.Password})
 
 	if err != nil {
 		if errors.Is(err, cases.ErrLoginFailed) {
@@ -107,3 +117,43 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		Token:    resp.Token,
 	})
 }
+
+// IssueTicket exchanges the JWT for a single-use WebSocket ticket
+// @Summary Issue a WebSocket ticket
+// @Description Returns a short-lived single-use ticket for the matchmaker or for one game. Pass it as the ticket query parameter when opening the WebSocket instead of the JWT.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TicketRequest true "Ticket scope"
+// @Success 201 {object} TicketResponse "Ticket issued"
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /ticket [post]
+func (h *AuthHandler) IssueTicket(c echo.Context) error {
+	r := new(TicketRequest)
+	if err := c.Bind(r); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	resp, err := h.useCase.IssueTicket(c.Request().Context(), props.IssueTicketReq{
+		PlayerId: c.Get("playerId").(string),
+		Scope:    r.Scope,
+		GameId:   r.GameID,
+	})
+
+	if err != nil {
+		if errors.Is(err, cases.ErrInvalidTicketScope) {
+			return c.String(http.StatusBadRequest, "Invalid scope")
+		}
+		return internalServerError
+	}
+
+	return c.JSON(http.StatusCreated, TicketResponse{
+		Ticket:    resp.Ticket,
+		ExpiresIn: int(resp.ExpiresIn.Seconds()),
+	})
+}
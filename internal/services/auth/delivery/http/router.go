@@ -1,15 +1,17 @@
 package http
 
 import (
+	"github.com/MommusWinner/MicroDurak/lib/jwt"
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func AddRoutes(e *echo.Echo, authHandler *AuthHandler) {
+func AddRoutes(e *echo.Echo, authHandler *AuthHandler, jwtPublic string) {
 	// Swagger documentation
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	// API routes
 	e.POST("/api/v1/auth/login", authHandler.Login)
 	e.POST("/api/v1/auth/register", authHandler.Register)
+	e.POST("/api/v1/auth/ticket", authHandler.IssueTicket, jwt.AuthMiddleware(jwtPublic))
 }
@@ -3,7 +3,8 @@ package cases
 import "errors"
 
 var (
-	ErrInternal          = errors.New("Server internal error")
-	ErrLoginFailed       = errors.New("Login failed")
-	ErrEmailAlreadyTaken = errors.New("Email already taken")
+	ErrInternal           = errors.New("Server internal error")
+	ErrLoginFailed        = errors.New("Login failed")
+	ErrEmailAlreadyTaken  = errors.New("Email already taken")
+	ErrInvalidTicketScope = errors.New("Invalid ticket scope")
 )
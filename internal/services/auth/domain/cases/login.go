@@ -1,6 +1,8 @@
 package cases
 
 import (
+	"context"
+
 	"github.com/MommusWinner/MicroDurak/internal/contracts/players/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/props"
@@ -21,11 +23,11 @@ func NewAuthUseCase(ctx domain.Context, playersClient players.PlayersClient, smt
 	}
 }
 
-func (uc *AuthUseCase) Login(args props.LoginReq) (resp props.LoginResp, err error) {
+func (uc *AuthUseCase) Login(ctx context.Context, args props.LoginReq) (resp props.LoginResp, err error) {
 	user, err := uc.ctx.Connection().AuthRepository().GetByEmail(args.Email)
 
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -42,7 +44,7 @@ func (uc *AuthUseCase) Login(args props.LoginReq) (resp props.LoginResp, err err
 
 	jwt, err := utils.GenerateToken(uc.ctx.Config().GetJwtPrivate(), user.PlayerId.String())
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -11,10 +11,10 @@ import (
 	"github.com/MommusWinner/MicroDurak/internal/services/auth/utils"
 )
 
-func (uc *AuthUseCase) Register(args props.RegisterReq) (resp props.RegisterResp, err error) {
+func (uc *AuthUseCase) Register(ctx context.Context, args props.RegisterReq) (resp props.RegisterResp, err error) {
 	user, err := uc.ctx.Connection().AuthRepository().GetByEmail(args.Email)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -24,49 +24,48 @@ func (uc *AuthUseCase) Register(args props.RegisterReq) (resp props.RegisterResp
 		return
 	}
 
-	rep, err := uc.playersClient.CreatePlayer(context.Background(), &players.CreatePlayerRequest{
+	rep, err := uc.playersClient.CreatePlayer(ctx, &players.CreatePlayerRequest{
 		Name: args.Name,
 		Age:  int32(args.Age),
 	})
 
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
 
 	if uc.smtp == nil {
-		uc.ctx.Logger().Error("Smtp is nil")
+		uc.ctx.Logger().ErrorContext(ctx, "Smtp is nil")
 		err = ErrInternal
 		return
 	} else {
 		err = uc.smtp.Send(args.Email, args.Name)
-		uc.ctx.Logger().Info("Send email")
 		if err != nil {
-			uc.ctx.Logger().Error(err.Error())
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to send email", "email", args.Email, "error", err.Error())
 		}
 	}
 
 	hashedPassword, err := utils.HashPassword(args.Password)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
 
 	playerId, err := uuid.Parse(rep.Id)
-	uc.ctx.Logger().Info(playerId.String())
+	uc.ctx.Logger().InfoContext(ctx, "Register player", "player_id", playerId.String())
 	err = uc.ctx.Connection().AuthRepository().Add(&models.AuthUser{PlayerId: playerId, Email: args.Email, Password: hashedPassword})
 
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
 
 	jwt, err := utils.GenerateToken(uc.ctx.Config().GetJwtPrivate(), playerId.String())
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -0,0 +1,41 @@
+package cases
+
+import (
+	"context"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/auth/domain/props"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
+)
+
+const (
+	TicketScopeMatchmaker = "matchmaker"
+	TicketScopeGame       = "game"
+)
+
+// IssueTicket exchanges an authenticated player for a single-use ticket that
+// opens the matchmaker WebSocket or the WebSocket of one game.
+func (uc *AuthUseCase) IssueTicket(ctx context.Context, args props.IssueTicketReq) (resp props.IssueTicketResp, err error) {
+	var scope string
+	switch {
+	case args.Scope == TicketScopeMatchmaker:
+		scope = ticket.ScopeMatchmaker
+	case args.Scope == TicketScopeGame && args.GameId != "":
+		scope = ticket.GameScope(args.GameId)
+	default:
+		err = ErrInvalidTicketScope
+		return
+	}
+
+	issued, err := uc.ctx.Connection().Tickets().Issue(ctx, args.PlayerId, scope)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
+		err = ErrInternal
+		return
+	}
+
+	resp = props.IssueTicketResp{
+		Ticket:    issued,
+		ExpiresIn: uc.ctx.Config().GetTicketTTL(),
+	}
+	return
+}
@@ -4,4 +4,5 @@ import "github.com/MommusWinner/MicroDurak/internal/services/auth/domain/reposit
 
 type Connection interface {
 	AuthRepository() repositories.AuthRepository
+	Tickets() Tickets
 }
@@ -1,14 +1,24 @@
 package infra
 
+import (
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
+)
+
 type Config interface {
 	GetJwtPrivate() string
+	GetJwtPublic() string
 	GetPlayersURL() string
 	GetPort() string
 	GetDatabaseURL() string
 	GetLogLevel() string
+	GetTracing() tracing.Config
 	GetEmailFrom() string
 	GetSMTPHost() string
 	GetSMTPPass() string
 	GetSMTPPort() int
 	GetSMTPUser() string
+	GetRedisURL() string
+	GetTicketTTL() time.Duration
 }
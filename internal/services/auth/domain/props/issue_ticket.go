@@ -0,0 +1,14 @@
+package props
+
+import "time"
+
+type IssueTicketReq struct {
+	PlayerId string
+	Scope    string
+	GameId   string
+}
+
+type IssueTicketResp struct {
+	Ticket    string
+	ExpiresIn time.Duration
+}
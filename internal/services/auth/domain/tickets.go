@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Tickets issues short-lived single-use tickets that open a WebSocket in
+// place of the JWT.
+type Tickets interface {
+	Issue(ctx context.Context, playerId, scope string) (string, error)
+}
@@ -1,25 +1,36 @@
 package config
 
 import (
-	"github.com/alecthomas/kong"
 	"log"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 )
 
 import ()
 
 type Config struct {
 	JWTPrivate  string `help:"Base64 Private key for the jwt"       env:"JWT_PRIVATE" required:"true"`
+	JWTPublic   string `help:"Base64 Public key for the jwt"        env:"JWT_PUBLIC" required:"true"`
 	PlayersURL  string `help:"URL pointing to the Players Service"  env:"PLAYERS_URL" required:"true"`
 	Port        string `help:"Port to listen on"                    env:"PORT" default:"8080"`
 	DatabaseURL string `help:"Database connection URL"              env:"DATABASE_URL" required:"true"`
 	LogLevel    string `help:"Log level (debug, info, warn, error)" env:"LOG_LEVEL" default:"info"`
 
+	// WebSocket tickets
+	RedisURL  string        `help:"Redis URL for WebSocket tickets"  env:"REDIS_URL" required:"true"`
+	TicketTTL time.Duration `help:"How long a WebSocket ticket lives" env:"TICKET_TTL" default:"30s"`
+
 	//SMTP
 	EmailFrom string `env:"EMAIL_FROM" required:"true"`
 	SMTPHost  string `env:"SMTP_HOST"  required:"true"`
 	SMTPPass  string `env:"SMTP_PASS"  required:"true"`
 	SMTPPort  int    `env:"SMTP_PORT"  required:"true"`
 	SMTPUser  string `env:"SMTP_USER"  required:"true"`
+
+	Tracing tracing.Config `embed:""`
 }
 
 // func Load() (*Config, error) {
@@ -57,6 +68,10 @@ func (s *Config) GetJwtPrivate() string {
 	return s.JWTPrivate
 }
 
+func (s *Config) GetJwtPublic() string {
+	return s.JWTPublic
+}
+
 func (s *Config) GetPlayersURL() string {
 	return s.PlayersURL
 }
@@ -72,6 +87,10 @@ func (s *Config) GetLogLevel() string {
 	return s.LogLevel
 }
 
+func (s *Config) GetTracing() tracing.Config {
+	return s.Tracing
+}
+
 func (s *Config) GetEmailFrom() string {
 	return s.EmailFrom
 }
@@ -91,3 +110,11 @@ func (s *Config) GetSMTPPort() int {
 func (s *Config) GetSMTPUser() string {
 	return s.SMTPUser
 }
+
+func (s *Config) GetRedisURL() string {
+	return s.RedisURL
+}
+
+func (s *Config) GetTicketTTL() time.Duration {
+	return s.TicketTTL
+}
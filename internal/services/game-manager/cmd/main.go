@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,15 +10,31 @@ import (
 
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/core"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/delivery/http"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"github.com/labstack/echo/v4"
 )
 
 func main() {
 	e := echo.New()
 
+	// WebSocket sessions are hijacked connections that Shutdown does not
+	// wait for; cancelling their base context closes them with "going away".
+	serverCtx, closeSessions := context.WithCancel(context.Background())
+	e.Server.BaseContext = func(net.Listener) context.Context { return serverCtx }
+
 	di := core.NewDi()
 	defer core.DisposeCtx(di.Ctx.(*core.Ctx))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "game-manager", di.Ctx.Config().GetTracing())
+	if err != nil {
+		di.Ctx.Logger().Error("Failed to set up tracing", "error", err.Error())
+		return
+	}
+	defer shutdownTracing(context.Background())
+	e.Use(tracing.Middleware("game-manager"))
+	e.Use(logging.Middleware(di.Ctx.Logger()))
+
 	http.AddRoutes(e, di.Handler, di.Ctx)
 
 	errChan := make(chan error, 1)
@@ -34,9 +51,11 @@ func main() {
 	select {
 	case err := <-errChan:
 		di.Ctx.Logger().Error("Server error", "error", err.Error())
+		closeSessions()
 		shutdown(e, di)
 	case <-quit:
 		di.Ctx.Logger().Info("Shutting down game manager service...")
+		closeSessions()
 		shutdown(e, di)
 		di.Ctx.Logger().Info("Game manager service stopped gracefully")
 	}
@@ -0,0 +1,235 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	chatChannelPrefix = "game-manager:chat:"
+	chatHistoryKeyFmt = "game-manager:chat-history:%s"
+	chatMutedKeyFmt   = "game-manager:chat-muted:%s:%s"
+	// chat state outlives any reasonable game
+	chatTTL = 24 * time.Hour
+
+	subscribeTimeout = 5 * time.Second
+)
+
+type chatHandler struct {
+	handle func(domain.ChatMessage)
+}
+
+// redisChat shares a single pub/sub connection between all sessions of the
+// pod and subscribes to the channel of a game while anyone listens to it.
+type redisChat struct {
+	client      *redis.Client
+	pubsub      *redis.PubSub
+	historySize int64
+
+	mu       sync.Mutex
+	handlers map[string]map[*chatHandler]struct{}
+	pending  map[string][]chan struct{}
+	closed   bool
+}
+
+func NewChat(client *redis.Client, historySize int) domain.Chat {
+	c := &redisChat{
+		client:      client,
+		pubsub:      client.Subscribe(context.Background()),
+		historySize: int64(historySize),
+		handlers:    make(map[string]map[*chatHandler]struct{}),
+		pending:     make(map[string][]chan struct{}),
+	}
+	go c.listen()
+
+	return c
+}
+
+func chatChannel(gameId string) string {
+	return chatChannelPrefix + gameId
+}
+
+func (c *redisChat) Publish(ctx context.Context, gameId string, message domain.ChatMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	historyKey := fmt.Sprintf(chatHistoryKeyFmt, gameId)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, historyKey, data)
+		pipe.LTrim(ctx, historyKey, -c.historySize, -1)
+		pipe.Expire(ctx, historyKey, chatTTL)
+		pipe.Publish(ctx, chatChannel(gameId), data)
+		return nil
+	})
+	return err
+}
+
+// Subscribe returns once redis confirmed the subscription, so messages
+// published afterwards are not missed.
+func (c *redisChat) Subscribe(gameId string, handle func(domain.ChatMessage)) (func(), error) {
+	channel := chatChannel(gameId)
+	h := &chatHandler{handle: handle}
+
+	c.mu.Lock()
+	handlers, ok := c.handlers[channel]
+	if !ok {
+		handlers = make(map[*chatHandler]struct{})
+		c.handlers[channel] = handlers
+	}
+	handlers[h] = struct{}{}
+
+	// later subscribers also wait while the first confirmation is pending
+	var confirmed chan struct{}
+	if _, waiting := c.pending[channel]; !ok || waiting {
+		confirmed = make(chan struct{})
+		c.pending[channel] = append(c.pending[channel], confirmed)
+	}
+	if !ok {
+		if err := c.pubsub.Subscribe(context.Background(), channel); err != nil {
+			delete(c.handlers, channel)
+			delete(c.pending, channel)
+			c.mu.Unlock()
+			return nil, err
+		}
+	}
+	c.mu.Unlock()
+
+	if confirmed != nil {
+		select {
+		case <-confirmed:
+		case <-time.After(subscribeTimeout):
+			slog.Error("Chat subscription is not confirmed", "channel", channel)
+		}
+	}
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		handlers := c.handlers[channel]
+		if _, ok := handlers[h]; !ok {
+			return
+		}
+		delete(handlers, h)
+		if len(handlers) == 0 {
+			delete(c.handlers, channel)
+			if err := c.pubsub.Unsubscribe(context.Background(), channel); err != nil {
+				slog.Error("Failed to unsubscribe from chat", "channel", channel, "error", err)
+			}
+		}
+	}, nil
+}
+
+func (c *redisChat) History(ctx context.Context, gameId string) ([]domain.ChatMessage, error) {
+	values, err := c.client.LRange(ctx, fmt.Sprintf(chatHistoryKeyFmt, gameId), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.ChatMessage, 0, len(values))
+	for _, value := range values {
+		var message domain.ChatMessage
+		if err := json.Unmarshal([]byte(value), &message); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (c *redisChat) Muted(ctx context.Context, gameId string, userId string) ([]string, error) {
+	return c.client.SMembers(ctx, fmt.Sprintf(chatMutedKeyFmt, gameId, userId)).Result()
+}
+
+func (c *redisChat) SetMuted(ctx context.Context, gameId string, userId string, targetId string, muted bool) error {
+	key := fmt.Sprintf(chatMutedKeyFmt, gameId, userId)
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if muted {
+			pipe.SAdd(ctx, key, targetId)
+		} else {
+			pipe.SRem(ctx, key, targetId)
+		}
+		pipe.Expire(ctx, key, chatTTL)
+		return nil
+	})
+	return err
+}
+
+func (c *redisChat) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	return c.pubsub.Close()
+}
+
+func (c *redisChat) listen() {
+	for {
+		msg, err := c.pubsub.Receive(context.Background())
+		if err != nil {
+			c.mu.Lock()
+			closed := c.closed
+			c.mu.Unlock()
+			if closed || errors.Is(err, redis.ErrClosed) {
+				return
+			}
+			// the pub/sub connection resubscribes on the next receive
+			slog.Error("Chat receive failed", "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				c.confirm(m.Channel)
+			}
+		case *redis.Message:
+			c.dispatch(m)
+		}
+	}
+}
+
+func (c *redisChat) confirm(channel string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, confirmed := range c.pending[channel] {
+		close(confirmed)
+	}
+	delete(c.pending, channel)
+}
+
+func (c *redisChat) dispatch(m *redis.Message) {
+	if !strings.HasPrefix(m.Channel, chatChannelPrefix) {
+		return
+	}
+
+	var message domain.ChatMessage
+	if err := json.Unmarshal([]byte(m.Payload), &message); err != nil {
+		slog.Warn("Drop malformed chat message", "error", err)
+		return
+	}
+
+	c.mu.Lock()
+	handlers := make([]*chatHandler, 0, len(c.handlers[m.Channel]))
+	for h := range c.handlers[m.Channel] {
+		handlers = append(handlers, h)
+	}
+	c.mu.Unlock()
+
+	for _, h := range handlers {
+		h.handle(message)
+	}
+}
@@ -0,0 +1,80 @@
+package connection
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	"github.com/redis/go-redis/v9"
+)
+
+type redisGames struct {
+	client *redis.Client
+}
+
+func NewGames(client *redis.Client) domain.Games {
+	return &redisGames{client: client}
+}
+
+func (g *redisGames) read(ctx context.Context, gameId string) (*core.Game, error) {
+	game, err := core.ReadGame(ctx, g.client, gameId)
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrGameNotFound
+	}
+	return game, err
+}
+
+func (g *redisGames) Players(ctx context.Context, gameId string) ([]string, error) {
+	game, err := g.read(ctx, gameId)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]string, 0, len(game.Users))
+	for _, user := range game.Users {
+		players = append(players, user.Id)
+	}
+	return players, nil
+}
+
+func (g *redisGames) Snapshot(ctx context.Context, gameId, userId string) (domain.GameSnapshot, error) {
+	game, err := g.read(ctx, gameId)
+	if err != nil {
+		return domain.GameSnapshot{}, err
+	}
+
+	state, err := game.StateFor(userId)
+	if err != nil {
+		return domain.GameSnapshot{}, domain.ErrNotInGame
+	}
+
+	return domain.GameSnapshot{
+		State:  state,
+		Timers: game.Timers(),
+	}, nil
+}
+
+func (g *redisGames) ActiveGames(ctx context.Context, userId string) ([]domain.ActiveGame, error) {
+	gameIds, err := core.ActiveGameIds(ctx, g.client, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]domain.ActiveGame, 0, len(gameIds))
+	for _, gameId := range gameIds {
+		game, err := g.read(ctx, gameId)
+		if errors.Is(err, domain.ErrGameNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if game.IsEnded {
+			continue
+		}
+
+		games = append(games, domain.ActiveGame{GameId: game.Id, Status: game.Status()})
+	}
+	return games, nil
+}
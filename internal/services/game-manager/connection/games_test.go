@@ -0,0 +1,93 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGamesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	game, err := core.CreateNewGame([]string{"first", "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.StartAttackTimer()
+	game.AddEventToBuffer(core.NewStartGameEvent(core.GameStateResponse{}))
+	value, _ := json.Marshal(game)
+	server.Set("game:"+game.Id, string(value))
+
+	games := NewGames(client)
+
+	snapshot, err := games.Snapshot(ctx, game.Id, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State.Me.Id != "second" || len(snapshot.State.Me.Cards) != 6 {
+		t.Errorf("Expected the second player's view, got %+v", snapshot.State.Me)
+	}
+	if snapshot.State.EventSeq != 1 {
+		t.Errorf("Expected event sequence 1, got %d", snapshot.State.EventSeq)
+	}
+	if !snapshot.Timers.Attack.IsRunning || snapshot.Timers.Attack.EndAt == nil || snapshot.Timers.Defend.IsRunning {
+		t.Errorf("Unexpected timers: %+v", snapshot.Timers)
+	}
+	endAt := game.AttackTimerStartedAt.Add(core.DefaultGameSettings.TimeOver)
+	if attack := snapshot.Timers.Attack.EndAt; attack == nil || !attack.Equal(endAt) {
+		t.Errorf("Expected the attack timer to end at %v, got %v", endAt, attack)
+	}
+
+	if _, err := games.Snapshot(ctx, game.Id, "stranger"); !errors.Is(err, domain.ErrNotInGame) {
+		t.Errorf("Expected ErrNotInGame, got %v", err)
+	}
+	if _, err := games.Snapshot(ctx, "missing", "first"); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("Expected ErrGameNotFound, got %v", err)
+	}
+}
+
+func TestGamesActiveGames(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	game, err := core.CreateNewGameAndSaveInRedis(ctx, client, []string{"first", "second"}, core.DefaultGameSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	games := NewGames(client)
+
+	active, err := games.ActiveGames(ctx, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].GameId != game.Id || active[0].Status != core.GameStatusWaiting {
+		t.Fatalf("Expected the waiting game, got %+v", active)
+	}
+
+	game.EndGame(core.GameResultWin)
+	if err := core.SaveGame(ctx, game, client); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, player := range []string{"first", "second"} {
+		active, err := games.ActiveGames(ctx, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(active) != 0 {
+			t.Errorf("Ended game should be dropped for %s, got %+v", player, active)
+		}
+	}
+}
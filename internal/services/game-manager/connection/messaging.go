@@ -0,0 +1,79 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/infra"
+	"github.com/MommusWinner/MicroDurak/lib/amqppool"
+	"github.com/MommusWinner/MicroDurak/lib/transport"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+type messaging struct {
+	transport transport.Transport
+}
+
+func NewMessaging(t transport.Transport) domain.Messaging {
+	return &messaging{transport: t}
+}
+
+func (m *messaging) Subscribe(ctx context.Context, gameId string, userId string, processMessage func([]byte) error) (func(), error) {
+	return m.transport.SubscribeUpdates(ctx, gameId, userId, processMessage)
+}
+
+func (m *messaging) SendMessageToGame(ctx context.Context, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	return m.transport.SendCommand(ctx, message)
+}
+
+func Make(cfg infra.Config) (*amqppool.Connection, transport.Transport, error) {
+	conn, err := amqppool.Dial("game-manager", cfg.GetRabbitmqURL())
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to connect to rabbitmq due [%s]", err)
+	}
+
+	t, err := transport.NewRabbitMQ(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("unable to declare rabbitmq topology due [%s]", err)
+	}
+
+	return conn, t, nil
+}
+
+func MakeRedis(cfg infra.Config) (*redis.Client, error) {
+	opt, err := redis.ParseURL(cfg.GetRedisURL())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse redis URL due [%s]", err)
+	}
+
+	client := redis.NewClient(opt)
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("unable to instrument redis due [%s]", err)
+	}
+	return client, nil
+}
+
+// Close shuts down the pub/sub listeners of the sessions and the chat
+// before the connections they use.
+func Close(conn *amqppool.Connection, client *redis.Client, sessions domain.Sessions, chat domain.Chat) {
+	if sessions != nil {
+		sessions.Close()
+	}
+	if chat != nil {
+		chat.Close()
+	}
+	if client != nil {
+		client.Close()
+	}
+	if conn != nil {
+		conn.Close()
+	}
+}
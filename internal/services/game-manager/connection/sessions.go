@@ -0,0 +1,155 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionKeyFmt     = "game-manager:session:%s:%s"
+	sessionRevokedKey = "game-manager:sessions:revoked"
+)
+
+var (
+	// refresh the expiration only while the key still holds our session id
+	refreshSessionScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+	releaseSessionScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+// redisSessions stores the active session id of every player under a key that
+// expires unless refreshed, so sessions of a crashed pod do not block the
+// player forever. Takeovers are announced over pub/sub to every pod.
+type redisSessions struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	revoked map[string]chan struct{}
+}
+
+func NewSessions(client *redis.Client, ttl time.Duration) (domain.Sessions, error) {
+	pubsub := client.Subscribe(context.Background(), sessionRevokedKey)
+	// wait for the subscription, otherwise early takeovers are missed
+	if _, err := pubsub.Receive(context.Background()); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	s := &redisSessions{
+		client:  client,
+		pubsub:  pubsub,
+		ttl:     ttl,
+		revoked: make(map[string]chan struct{}),
+	}
+	go s.listen()
+
+	return s, nil
+}
+
+func sessionKey(gameId string, userId string) string {
+	return fmt.Sprintf(sessionKeyFmt, gameId, userId)
+}
+
+func (s *redisSessions) Acquire(ctx context.Context, gameId string, userId string, sessionId string, takeover bool) (<-chan struct{}, error) {
+	key := sessionKey(gameId, userId)
+
+	if !takeover {
+		ok, err := s.client.SetNX(ctx, key, sessionId, s.ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, domain.ErrSessionActive
+		}
+		return s.watch(sessionId), nil
+	}
+
+	revoked := s.watch(sessionId)
+
+	previous, err := s.client.SetArgs(ctx, key, sessionId, redis.SetArgs{TTL: s.ttl, Get: true}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.unwatch(sessionId)
+		return nil, err
+	}
+
+	if previous != "" && previous != sessionId {
+		if err := s.client.Publish(ctx, sessionRevokedKey, previous).Err(); err != nil {
+			// the previous session notices on its next refresh
+			slog.ErrorContext(ctx, "Failed to announce session takeover", "error", err)
+		}
+	}
+
+	return revoked, nil
+}
+
+func (s *redisSessions) Refresh(ctx context.Context, gameId string, userId string, sessionId string) error {
+	refreshed, err := refreshSessionScript.Run(ctx, s.client,
+		[]string{sessionKey(gameId, userId)},
+		sessionId, s.ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		return err
+	}
+	if refreshed == 0 {
+		return domain.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (s *redisSessions) Release(ctx context.Context, gameId string, userId string, sessionId string) error {
+	s.unwatch(sessionId)
+
+	return releaseSessionScript.Run(ctx, s.client,
+		[]string{sessionKey(gameId, userId)},
+		sessionId,
+	).Err()
+}
+
+func (s *redisSessions) Close() error {
+	return s.pubsub.Close()
+}
+
+func (s *redisSessions) watch(sessionId string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := make(chan struct{})
+	s.revoked[sessionId] = revoked
+	return revoked
+}
+
+func (s *redisSessions) unwatch(sessionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.revoked, sessionId)
+}
+
+func (s *redisSessions) listen() {
+	for msg := range s.pubsub.Channel() {
+		s.mu.Lock()
+		if revoked, ok := s.revoked[msg.Payload]; ok {
+			close(revoked)
+			delete(s.revoked, msg.Payload)
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,81 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestSessions(t *testing.T) domain.Sessions {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	sessions, err := NewSessions(client, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sessions.(*redisSessions).Close() })
+
+	return sessions
+}
+
+func TestSessionsReject(t *testing.T) {
+	ctx := context.Background()
+	sessions := newTestSessions(t)
+
+	if _, err := sessions.Acquire(ctx, "game", "user", "first", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.Acquire(ctx, "game", "user", "second", false); !errors.Is(err, domain.ErrSessionActive) {
+		t.Fatalf("Expected ErrSessionActive, got %v", err)
+	}
+
+	if err := sessions.Release(ctx, "game", "user", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.Acquire(ctx, "game", "user", "second", false); err != nil {
+		t.Fatalf("Session should be free after release, got %v", err)
+	}
+}
+
+func TestSessionsTakeover(t *testing.T) {
+	ctx := context.Background()
+	sessions := newTestSessions(t)
+
+	revoked, err := sessions.Acquire(ctx, "game", "user", "first", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.Acquire(ctx, "game", "user", "second", true); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-revoked:
+	case <-time.After(time.Second):
+		t.Fatal("First session was not revoked")
+	}
+
+	if err := sessions.Refresh(ctx, "game", "user", "first"); !errors.Is(err, domain.ErrSessionRevoked) {
+		t.Errorf("Expected ErrSessionRevoked, got %v", err)
+	}
+	if err := sessions.Refresh(ctx, "game", "user", "second"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// a stale release must not drop the new owner
+	if err := sessions.Release(ctx, "game", "user", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.Refresh(ctx, "game", "user", "second"); err != nil {
+		t.Errorf("Unexpected error after stale release: %v", err)
+	}
+}
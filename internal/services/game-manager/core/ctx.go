@@ -2,20 +2,28 @@ package core
 
 import (
 	"log/slog"
-	"os"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/connection"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/infra/config"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/MommusWinner/MicroDurak/lib/amqppool"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
+	"github.com/MommusWinner/MicroDurak/lib/transport"
+	"github.com/redis/go-redis/v9"
 )
 
 type Ctx struct {
 	cfg       infra.Config
 	logger    *slog.Logger
 	messaging domain.Messaging
-	conn      *amqp.Connection
+	sessions  domain.Sessions
+	chat      domain.Chat
+	games     domain.Games
+	tickets   domain.Tickets
+	conn      *amqppool.Connection
+	redis     *redis.Client
 }
 
 func (c *Ctx) Config() infra.Config {
@@ -30,34 +38,85 @@ func (c *Ctx) Messaging() domain.Messaging {
 	return c.messaging
 }
 
+func (c *Ctx) Sessions() domain.Sessions {
+	return c.sessions
+}
+
+func (c *Ctx) Chat() domain.Chat {
+	return c.chat
+}
+
+func (c *Ctx) Games() domain.Games {
+	return c.games
+}
+
+func (c *Ctx) Tickets() domain.Tickets {
+	return c.tickets
+}
+
 func (c *Ctx) Make() domain.Context {
 	return &Ctx{
 		cfg:       c.cfg,
 		logger:    c.logger,
 		messaging: c.messaging,
+		sessions:  c.sessions,
+		chat:      c.chat,
+		games:     c.games,
+		tickets:   c.tickets,
 		conn:      c.conn,
+		redis:     c.redis,
 	}
 }
 
 func InitCtx() *Ctx {
 	cfg := config.Make()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger, err := logging.New("game-manager", cfg.GetLogLevel())
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
+
+	conn, t, err := connection.Make(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	client, err := connection.MakeRedis(cfg)
+	if err != nil {
+		panic(err)
+	}
 
-	conn, err := connection.Make(cfg)
+	ctx, err := NewCtx(cfg, logger, t, client)
 	if err != nil {
 		panic(err)
 	}
+	ctx.conn = conn
+
+	return ctx
+}
 
-	messaging := connection.NewMessaging(conn)
+// NewCtx builds a context over an already constructed transport and redis
+// client, e.g. an in-memory transport when game-manager runs in the same
+// process as the game.
+func NewCtx(cfg infra.Config, logger *slog.Logger, t transport.Transport, client *redis.Client) (*Ctx, error) {
+	// a session is refreshed on every ping, so it survives a few lost ones
+	sessions, err := connection.NewSessions(client, 3*cfg.GetPingInterval())
+	if err != nil {
+		return nil, err
+	}
 
 	return &Ctx{
 		cfg:       cfg,
 		logger:    logger,
-		messaging: messaging,
-		conn:      conn,
-	}
+		messaging: connection.NewMessaging(t),
+		sessions:  sessions,
+		chat:      connection.NewChat(client, cfg.GetChatHistorySize()),
+		games:     connection.NewGames(client),
+		tickets:   ticket.NewStore(client, ticket.DefaultTTL),
+		redis:     client,
+	}, nil
 }
 
 func DisposeCtx(ctx *Ctx) {
-	connection.Close(ctx.conn)
+	connection.Close(ctx.conn, ctx.redis, ctx.sessions, ctx.chat)
 }
@@ -17,8 +17,10 @@ func NewDi() *Di {
 
 	var (
 		metrics              = http.NewMetricsAdapter()
-		handleMessageUseCase = cases.NewHandleMessageUseCase(ctx, metrics)
-		handler              = http.NewGameManagerHandler(ctx, handleMessageUseCase)
+		chatUseCase          = cases.NewChatUseCase(ctx, cases.NewWordListFilter(ctx.Config().GetChatBannedWords()))
+		handleMessageUseCase = cases.NewHandleMessageUseCase(ctx, metrics, chatUseCase)
+		gameStateUseCase     = cases.NewGameStateUseCase(ctx)
+		handler              = http.NewGameManagerHandler(ctx, handleMessageUseCase, gameStateUseCase)
 	)
 
 	return &Di{
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	pb "github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	SubprotocolJSON     = "durak.v1.json"
+	SubprotocolProtobuf = "durak.v1.protobuf"
+)
+
+var (
+	ErrUnknownMessage = errors.New("unknown message in pack")
+)
+
+// codec translates between the wire format negotiated with the client and
+// the JSON format spoken by the game service.
+type codec interface {
+	MessageType() int
+	DecodeCommand(frame []byte) ([]byte, error)
+	EncodePack(pack []byte) ([]byte, error)
+}
+
+func codecForSubprotocol(subprotocol string) codec {
+	if subprotocol == SubprotocolProtobuf {
+		return protobufCodec{}
+	}
+	return jsonCodec{}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MessageType() int {
+	return websocket.TextMessage
+}
+
+func (jsonCodec) DecodeCommand(frame []byte) ([]byte, error) {
+	return frame, nil
+}
+
+func (jsonCodec) EncodePack(pack []byte) ([]byte, error) {
+	return pack, nil
+}
+
+type protobufCodec struct{}
+
+var (
+	protoJSONReader = protojson.UnmarshalOptions{DiscardUnknown: true}
+	protoJSONWriter = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+func (protobufCodec) MessageType() int {
+	return websocket.BinaryMessage
+}
+
+func (protobufCodec) DecodeCommand(frame []byte) ([]byte, error) {
+	var command pb.Command
+	if err := proto.Unmarshal(frame, &command); err != nil {
+		return nil, err
+	}
+
+	return protoJSONWriter.Marshal(&command)
+}
+
+func (protobufCodec) EncodePack(pack []byte) ([]byte, error) {
+	var raw struct {
+		Messages  []json.RawMessage `json:"messages"`
+		GameState json.RawMessage   `json:"game_state"`
+	}
+	if err := json.Unmarshal(pack, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &pb.MessagePack{
+		Messages: make([]*pb.Message, 0, len(raw.Messages)),
+	}
+	// chat packs carry no game state
+	if len(raw.GameState) > 0 && string(raw.GameState) != "null" {
+		result.GameState = &pb.GameStateResponse{}
+		if err := protoJSONReader.Unmarshal(raw.GameState, result.GameState); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rawMessage := range raw.Messages {
+		message, err := decodePackMessage(rawMessage)
+		if err != nil {
+			return nil, err
+		}
+		result.Messages = append(result.Messages, message)
+	}
+
+	return proto.Marshal(result)
+}
+
+// decodePackMessage tells command responses and game events apart by their
+// discriminating keys: events carry "event", responses carry "state". Chat
+// events are told apart by their name.
+func decodePackMessage(data []byte) (*pb.Message, error) {
+	var keys struct {
+		Event      *string    `json:"event"`
+		State      *struct{}  `json:"state"`
+		TimerEndAt *time.Time `json:"timer_end_at"`
+		SentAt     *time.Time `json:"sent_at"`
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case keys.Event != nil && isChatEvent(*keys.Event):
+		chat := &pb.ChatEvent{}
+		if err := protoJSONReader.Unmarshal(data, chat); err != nil {
+			return nil, err
+		}
+		if keys.SentAt != nil {
+			chat.SentAtUnixMs = keys.SentAt.UnixMilli()
+		}
+		return &pb.Message{Payload: &pb.Message_Chat{Chat: chat}}, nil
+	case keys.Event != nil && *keys.Event == domain.EventCommandRejected:
+		rejected := &pb.CommandRejected{}
+		if err := protoJSONReader.Unmarshal(data, rejected); err != nil {
+			return nil, err
+		}
+		return &pb.Message{Payload: &pb.Message_Rejected{Rejected: rejected}}, nil
+	case keys.Event != nil:
+		event := &pb.GameEvent{}
+		if err := protoJSONReader.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		if keys.TimerEndAt != nil {
+			event.TimerEndAtUnixMs = keys.TimerEndAt.UnixMilli()
+		}
+		return &pb.Message{Payload: &pb.Message_Event{Event: event}}, nil
+	case keys.State != nil:
+		response := &pb.CommandResponse{}
+		if err := protoJSONReader.Unmarshal(data, response); err != nil {
+			return nil, err
+		}
+		return &pb.Message{Payload: &pb.Message_Response{Response: response}}, nil
+	}
+
+	return nil, ErrUnknownMessage
+}
+
+func isChatEvent(event string) bool {
+	switch event {
+	case domain.EventChat, domain.EventEmote, domain.EventMuted, domain.EventUnmuted, domain.EventChatRejected:
+		return true
+	}
+	return false
+}
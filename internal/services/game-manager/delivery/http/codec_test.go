@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pb "github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProtobufCodecDecodeCommand(t *testing.T) {
+	frame, err := proto.Marshal(&pb.Command{
+		GameId: "game",
+		Action: core.ACTION_ATTACK,
+		UserId: "user",
+		Card:   &pb.Card{Suit: 2, Rank: 11},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := protobufCodec{}.DecodeCommand(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var command core.AttackCommand
+	if err := json.Unmarshal(message, &command); err != nil {
+		t.Fatal(err)
+	}
+
+	if command.GameId != "game" || command.UserId != "user" || command.Action != core.ACTION_ATTACK {
+		t.Errorf("Unexpected command: %+v", command)
+	}
+	if command.Card != (core.Card{Suit: 2, Rank: 11}) {
+		t.Errorf("Unexpected card: %+v", command.Card)
+	}
+}
+
+func TestProtobufCodecEncodePack(t *testing.T) {
+	game, err := core.CreateNewGame([]string{"first", "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command, _ := json.Marshal(core.Command{GameId: game.Id, Action: core.ACTION_READY, UserId: "first"})
+	packs, err := game.HandleMessage(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frame, err := protobufCodec{}.EncodePack(packs["first"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pack pb.MessagePack
+	if err := proto.Unmarshal(frame, &pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pack.Messages) != 2 {
+		t.Fatalf("Expected response and ready event, got %d messages", len(pack.Messages))
+	}
+
+	response := pack.Messages[0].GetResponse()
+	if response == nil || response.Command.GetAction() != core.ACTION_READY || response.Error != core.ERROR_EMPTY {
+		t.Errorf("Unexpected response: %v", pack.Messages[0])
+	}
+
+	event := pack.Messages[1].GetEvent()
+	if event == nil || event.Event != core.EVENT_READY || event.UserId != "first" {
+		t.Errorf("Unexpected event: %v", pack.Messages[1])
+	}
+
+	state := pack.GameState
+	if state.Me.GetId() != "first" || len(state.Me.Cards) != 6 || len(state.Users) != 2 {
+		t.Errorf("Unexpected game state: %v", state)
+	}
+	if int(state.DeckLength) != len(game.Deck) || int(state.TrumpSuit) != game.TrumpSuit {
+		t.Errorf("Unexpected deck in game state: %v", state)
+	}
+	if state.EventSeq != game.EventSeq {
+		t.Errorf("Expected event sequence %d, got %d", game.EventSeq, state.EventSeq)
+	}
+}
+
+func TestProtobufCodecEncodeChatPack(t *testing.T) {
+	sentAt := time.UnixMilli(1700000000000).UTC()
+	pack, _ := json.Marshal(map[string]any{
+		"messages": []any{domain.ChatMessage{Event: domain.EventChat, UserId: "first", Text: "hi", SentAt: sentAt}},
+	})
+
+	frame, err := protobufCodec{}.EncodePack(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded pb.MessagePack
+	if err := proto.Unmarshal(frame, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.GameState != nil {
+		t.Errorf("Chat pack should have no game state, got %v", decoded.GameState)
+	}
+	chat := decoded.Messages[0].GetChat()
+	if chat == nil || chat.Event != domain.EventChat || chat.Text != "hi" || chat.SentAtUnixMs != sentAt.UnixMilli() {
+		t.Errorf("Unexpected chat message: %v", decoded.Messages[0])
+	}
+}
+
+func TestCodecForSubprotocol(t *testing.T) {
+	if _, ok := codecForSubprotocol("").(jsonCodec); !ok {
+		t.Error("JSON should be the default encoding")
+	}
+	if _, ok := codecForSubprotocol(SubprotocolProtobuf).(protobufCodec); !ok {
+		t.Error("Protobuf subprotocol should select the protobuf codec")
+	}
+}
@@ -1,64 +1,128 @@
 package http
 
 import (
+	"errors"
+
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/cases"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/props"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	"github.com/MommusWinner/MicroDurak/lib/origin"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 )
 
-var (
-	upgrader = websocket.Upgrader{}
-)
-
 type GameManagerHandler struct {
 	ctx                  domain.Context
 	handleMessageUseCase *cases.HandleMessageUseCase
+	gameStateUseCase     *cases.GameStateUseCase
+	upgrader             websocket.Upgrader
 }
 
-func NewGameManagerHandler(ctx domain.Context, handleMessageUseCase *cases.HandleMessageUseCase) *GameManagerHandler {
+func NewGameManagerHandler(ctx domain.Context, handleMessageUseCase *cases.HandleMessageUseCase, gameStateUseCase *cases.GameStateUseCase) *GameManagerHandler {
 	return &GameManagerHandler{
 		ctx:                  ctx,
 		handleMessageUseCase: handleMessageUseCase,
+		gameStateUseCase:     gameStateUseCase,
+		upgrader:             newUpgrader(ctx.Config().GetAllowedOrigins()),
 	}
 }
 
-func (h *GameManagerHandler) Connect(c echo.Context) error {
-	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+type ActiveGame struct {
+	GameId string `json:"game_id"`
+	Status string `json:"status"`
+}
+
+type ActiveGamesResponse struct {
+	Games []ActiveGame `json:"games"`
+}
+
+type GameStateResponse struct {
+	GameState core.GameStateResponse `json:"game_state"`
+	Timers    core.TimersResponse    `json:"timers"`
+}
+
+// newUpgrader only selects the encoding subprotocols, so a token offered as
+// a subprotocol is never echoed back.
+func newUpgrader(allowedOrigins []string) websocket.Upgrader {
+	return websocket.Upgrader{
+		Subprotocols: []string{SubprotocolJSON, SubprotocolProtobuf},
+		CheckOrigin:  origin.Checker(allowedOrigins),
+	}
+}
 
+func (h *GameManagerHandler) Connect(c echo.Context) error {
 	userId, ok := c.Get("playerId").(string)
 	if !ok {
-		if ws != nil {
-			ws.Close()
-		}
 		return echo.NewHTTPError(401, "Unauthorized")
 	}
 
 	gameId := c.Param("gameId")
 	if gameId == "" {
-		c.Response().Status = 400
-		if ws != nil {
-			ws.Close()
-		}
-		return nil
+		return echo.NewHTTPError(400, "Missing game id")
 	}
 
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
-		if ws != nil {
-			ws.Close()
-		}
-		return err
+		// the upgrader has already written the error response
+		return nil
 	}
 	defer ws.Close()
 
-	wsAdapter := NewWebSocketAdapter(ws)
+	cfg := h.ctx.Config()
+	wsAdapter := NewWebSocketAdapter(ws, cfg.GetPongWait(), cfg.GetWriteWait(), int64(cfg.GetMaxFrameSize()))
 
-	err = h.handleMessageUseCase.ConnectWebSocket(props.ConnectWebSocketReq{
+	return h.handleMessageUseCase.ConnectWebSocket(c.Request().Context(), props.ConnectWebSocketReq{
 		GameId:    gameId,
 		UserId:    userId,
 		WebSocket: wsAdapter,
 	})
+}
 
-	return err
+// GameState returns the caller's view of the game. event_seq in the game
+// state tells which WebSocket packs are already included in it.
+func (h *GameManagerHandler) GameState(c echo.Context) error {
+	userId, ok := c.Get("playerId").(string)
+	if !ok {
+		return echo.NewHTTPError(401, "Unauthorized")
+	}
+
+	resp, err := h.gameStateUseCase.GetGameState(c.Request().Context(), props.GetGameStateReq{
+		GameId: c.Param("gameId"),
+		UserId: userId,
+	})
+	if errors.Is(err, domain.ErrGameNotFound) {
+		return echo.NewHTTPError(404, "Game not found")
+	}
+	if errors.Is(err, domain.ErrNotInGame) {
+		return echo.NewHTTPError(403, "Not a player of the game")
+	}
+	if err != nil {
+		return echo.NewHTTPError(500, "Internal Server Error")
+	}
+
+	return c.JSON(200, GameStateResponse{
+		GameState: resp.State,
+		Timers:    resp.Timers,
+	})
+}
+
+// ActiveGames returns the games the caller is seated in, so a restarted
+// client can find and rejoin its game.
+func (h *GameManagerHandler) ActiveGames(c echo.Context) error {
+	userId, ok := c.Get("playerId").(string)
+	if !ok {
+		return echo.NewHTTPError(401, "Unauthorized")
+	}
+
+	resp, err := h.gameStateUseCase.GetActiveGames(c.Request().Context(), props.GetActiveGamesReq{UserId: userId})
+	if err != nil {
+		return echo.NewHTTPError(500, "Internal Server Error")
+	}
+
+	games := make([]ActiveGame, 0, len(resp.Games))
+	for _, game := range resp.Games {
+		games = append(games, ActiveGame{GameId: game.GameId, Status: game.Status})
+	}
+	return c.JSON(200, ActiveGamesResponse{Games: games})
 }
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"time"
+
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/metrics"
 )
@@ -18,3 +20,39 @@ func (m *metricsAdapter) IncPlayersConnected(podName, namespace string) {
 func (m *metricsAdapter) DecPlayersConnected(podName, namespace string) {
 	metrics.PlayersConnected.WithLabelValues(podName, namespace).Dec()
 }
+
+func (m *metricsAdapter) IncViolations(podName, namespace, reason string) {
+	metrics.Violations.WithLabelValues(podName, namespace, reason).Inc()
+}
+
+func (m *metricsAdapter) IncAbuseDisconnects(podName, namespace string) {
+	metrics.AbuseDisconnects.WithLabelValues(podName, namespace).Inc()
+}
+
+func (m *metricsAdapter) IncActiveGames(podName, namespace string) {
+	metrics.ActiveGames.WithLabelValues(podName, namespace).Inc()
+}
+
+func (m *metricsAdapter) DecActiveGames(podName, namespace string) {
+	metrics.ActiveGames.WithLabelValues(podName, namespace).Dec()
+}
+
+func (m *metricsAdapter) IncMessagesReceived(podName, namespace, messageType string) {
+	metrics.MessagesReceived.WithLabelValues(podName, namespace, messageType).Inc()
+}
+
+func (m *metricsAdapter) IncMessagesSent(podName, namespace, messageType string) {
+	metrics.MessagesSent.WithLabelValues(podName, namespace, messageType).Inc()
+}
+
+func (m *metricsAdapter) IncWebSocketErrors(podName, namespace, kind string) {
+	metrics.WebSocketErrors.WithLabelValues(podName, namespace, kind).Inc()
+}
+
+func (m *metricsAdapter) ObservePublishDuration(podName, namespace string, duration time.Duration) {
+	metrics.PublishDuration.WithLabelValues(podName, namespace).Observe(duration.Seconds())
+}
+
+func (m *metricsAdapter) ObserveCommandDuration(podName, namespace, action string, duration time.Duration) {
+	metrics.CommandDuration.WithLabelValues(podName, namespace, action).Observe(duration.Seconds())
+}
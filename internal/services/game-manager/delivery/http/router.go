@@ -3,9 +3,17 @@ package http
 import (
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/MommusWinner/MicroDurak/lib/jwt"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func AddRoutes(e *echo.Echo, handler *GameManagerHandler, ctx domain.Context) {
-	e.GET("/api/v1/game-manager/:gameId", handler.Connect, jwt.AuthMiddleware(ctx.Config().GetJWTPublic()))
+	tickets := jwt.WithTickets(func(c echo.Context, t string) (string, error) {
+		return ctx.Tickets().Consume(c.Request().Context(), t, ticket.GameScope(c.Param("gameId")))
+	})
+	e.GET("/api/v1/game-manager/games", handler.ActiveGames, jwt.AuthMiddleware(ctx.Config().GetJWTPublic()))
+	e.GET("/api/v1/game-manager/:gameId", handler.Connect, jwt.AuthMiddleware(ctx.Config().GetJWTPublic(), tickets))
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+	e.GET("/api/v1/game-manager/:gameId/state", handler.GameState, jwt.AuthMiddleware(ctx.Config().GetJWTPublic()))
 }
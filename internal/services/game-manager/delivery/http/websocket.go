@@ -1,25 +1,81 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/gorilla/websocket"
 )
 
 type webSocketAdapter struct {
-	conn *websocket.Conn
+	conn      *websocket.Conn
+	codec     codec
+	pongWait  time.Duration
+	writeWait time.Duration
 }
 
-func NewWebSocketAdapter(conn *websocket.Conn) domain.WebSocket {
-	return &webSocketAdapter{conn: conn}
+// NewWebSocketAdapter drops the connection when no frame or pong arrives
+// within pongWait or a frame exceeds maxFrameSize, and gives every write
+// writeWait to complete.
+func NewWebSocketAdapter(conn *websocket.Conn, pongWait time.Duration, writeWait time.Duration, maxFrameSize int64) domain.WebSocket {
+	conn.SetReadLimit(maxFrameSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	return &webSocketAdapter{
+		conn:      conn,
+		codec:     codecForSubprotocol(conn.Subprotocol()),
+		pongWait:  pongWait,
+		writeWait: writeWait,
+	}
 }
 
 func (w *webSocketAdapter) ReadMessage() (message []byte, err error) {
 	_, msg, err := w.conn.ReadMessage()
-	return msg, err
+	if errors.Is(err, websocket.ErrReadLimit) {
+		// the connection already sent the "message too big" close frame
+		return nil, domain.ErrFrameTooLarge
+	}
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+		return nil, domain.ErrClientClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.conn.SetReadDeadline(time.Now().Add(w.pongWait))
+
+	command, err := w.codec.DecodeCommand(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrMalformedFrame, err)
+	}
+	return command, nil
 }
 
 func (w *webSocketAdapter) WriteMessage(message []byte) error {
-	return w.conn.WriteMessage(1, message)
+	frame, err := w.codec.EncodePack(message)
+	if err != nil {
+		return err
+	}
+	w.conn.SetWriteDeadline(time.Now().Add(w.writeWait))
+	return w.conn.WriteMessage(w.codec.MessageType(), frame)
+}
+
+func (w *webSocketAdapter) Ping() error {
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.writeWait))
+}
+
+func (w *webSocketAdapter) CloseWithCode(code int, reason string) error {
+	// The peer may already be gone; the connection is closed either way.
+	w.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(w.writeWait),
+	)
+	return w.conn.Close()
 }
 
 func (w *webSocketAdapter) Close() error {
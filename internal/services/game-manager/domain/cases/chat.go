@@ -0,0 +1,268 @@
+package cases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/lib/ratelimit"
+)
+
+type ChatUseCase struct {
+	ctx    domain.Context
+	filter domain.ChatFilter
+}
+
+func NewChatUseCase(ctx domain.Context, filter domain.ChatFilter) *ChatUseCase {
+	return &ChatUseCase{
+		ctx:    ctx,
+		filter: filter,
+	}
+}
+
+// chatCommand is the part of a client command the chat cares about.
+type chatCommand struct {
+	Action   string `json:"action"`
+	Text     string `json:"text"`
+	Emote    string `json:"emote"`
+	TargetId string `json:"target_id"`
+}
+
+type chatRejected struct {
+	Event  string `json:"event"`
+	Action string `json:"action"`
+	Error  string `json:"error"`
+}
+
+type chatMuteChanged struct {
+	Event  string `json:"event"`
+	UserId string `json:"user_id"`
+}
+
+func isChatAction(action string) bool {
+	switch action {
+	case domain.ActionChat, domain.ActionEmote, domain.ActionMute, domain.ActionUnmute:
+		return true
+	}
+	return false
+}
+
+// chatMember is the chat state of one WebSocket session.
+type chatMember struct {
+	gameId  string
+	userId  string
+	limiter *ratelimit.TokenBucket
+	deliver func([]byte) error
+
+	// seatMu guards the seat and the chat subscription, which change once
+	// the player turns out to be seated.
+	seatMu      sync.Mutex
+	seated      bool
+	unsubscribe func()
+
+	mu    sync.Mutex
+	muted map[string]bool
+}
+
+func (m *chatMember) isMuted(userId string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted[userId]
+}
+
+func (m *chatMember) setMuted(userId string, muted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if muted {
+		m.muted[userId] = true
+	} else {
+		delete(m.muted, userId)
+	}
+}
+
+func (m *chatMember) isSeated() bool {
+	m.seatMu.Lock()
+	defer m.seatMu.Unlock()
+	return m.seated
+}
+
+func (m *chatMember) leave() {
+	m.seatMu.Lock()
+	defer m.seatMu.Unlock()
+	if m.unsubscribe != nil {
+		m.unsubscribe()
+		m.unsubscribe = nil
+	}
+}
+
+// Join subscribes deliver to the chat of the game and replays the history,
+// skipping muted players. Only seated players take part in the chat unless
+// spectators are allowed to read it. Chat failures are logged and leave the
+// game session usable.
+func (uc *ChatUseCase) Join(ctx context.Context, gameId string, userId string, deliver func([]byte) error) (*chatMember, func()) {
+	cfg := uc.ctx.Config()
+	member := &chatMember{
+		gameId:  gameId,
+		userId:  userId,
+		limiter: ratelimit.NewTokenBucket(cfg.GetChatBurst(), cfg.GetChatInterval()),
+		deliver: deliver,
+		muted:   make(map[string]bool),
+	}
+
+	uc.Refresh(ctx, member)
+	return member, member.leave
+}
+
+// Refresh checks the seat of a player who is not seated yet, as a failed
+// lookup or a game seating the player later changes it, and subscribes the
+// member to the chat with its muted players once it may read it.
+func (uc *ChatUseCase) Refresh(ctx context.Context, member *chatMember) {
+	member.seatMu.Lock()
+	defer member.seatMu.Unlock()
+
+	if !member.seated {
+		players, err := uc.ctx.Games().Players(ctx, member.gameId)
+		if err != nil {
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to load game players", "game_id", member.gameId, "error", err.Error())
+		}
+		member.seated = slices.Contains(players, member.userId)
+	}
+
+	if member.unsubscribe != nil || (!member.seated && !uc.ctx.Config().GetChatSpectators()) {
+		return
+	}
+
+	muted, err := uc.ctx.Chat().Muted(ctx, member.gameId, member.userId)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to load muted players", "error", err.Error())
+	}
+	for _, mutedId := range muted {
+		member.setMuted(mutedId, true)
+	}
+
+	unsubscribe, err := uc.ctx.Chat().Subscribe(member.gameId, func(message domain.ChatMessage) {
+		if member.isMuted(message.UserId) {
+			return
+		}
+		if pack, err := json.Marshal(statelessPack{Messages: []any{message}}); err == nil {
+			member.deliver(pack)
+		}
+	})
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to subscribe to chat", "error", err.Error())
+		return
+	}
+	member.unsubscribe = unsubscribe
+
+	history, err := uc.ctx.Chat().History(ctx, member.gameId)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to load chat history", "error", err.Error())
+	}
+	replay := make([]any, 0, len(history))
+	for _, message := range history {
+		if !member.isMuted(message.UserId) {
+			replay = append(replay, message)
+		}
+	}
+	if len(replay) > 0 {
+		if pack, err := json.Marshal(statelessPack{Messages: replay}); err == nil {
+			member.deliver(pack)
+		}
+	}
+}
+
+// Handle runs a chat command and returns the pack to send back to the
+// sender, if any. Chat messages reach the sender through the chat itself.
+func (uc *ChatUseCase) Handle(ctx context.Context, member *chatMember, command chatCommand) []byte {
+	var reply any
+	err := uc.handle(ctx, member, command)
+	switch {
+	case err == nil && (command.Action == domain.ActionMute || command.Action == domain.ActionUnmute):
+		event := domain.EventMuted
+		if command.Action == domain.ActionUnmute {
+			event = domain.EventUnmuted
+		}
+		reply = chatMuteChanged{Event: event, UserId: command.TargetId}
+	case err == nil:
+		return nil
+	default:
+		if !isChatError(err) {
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to handle chat command", "action", command.Action, "error", err.Error())
+			err = domain.ErrChatUnavailable
+		}
+		reply = chatRejected{Event: domain.EventChatRejected, Action: command.Action, Error: err.Error()}
+	}
+
+	pack, err := json.Marshal(statelessPack{Messages: []any{reply}})
+	if err != nil {
+		return nil
+	}
+	return pack
+}
+
+func (uc *ChatUseCase) handle(ctx context.Context, member *chatMember, command chatCommand) error {
+	if !member.isSeated() {
+		uc.Refresh(ctx, member)
+	}
+	if !member.isSeated() {
+		return domain.ErrChatNotSeated
+	}
+
+	cfg := uc.ctx.Config()
+	message := domain.ChatMessage{
+		UserId: member.userId,
+		SentAt: time.Now().UTC(),
+	}
+
+	switch command.Action {
+	case domain.ActionMute, domain.ActionUnmute:
+		if command.TargetId == "" || command.TargetId == member.userId {
+			return domain.ErrChatInvalid
+		}
+		muted := command.Action == domain.ActionMute
+		if err := uc.ctx.Chat().SetMuted(ctx, member.gameId, member.userId, command.TargetId, muted); err != nil {
+			return err
+		}
+		member.setMuted(command.TargetId, muted)
+		return nil
+	case domain.ActionEmote:
+		if !slices.Contains(cfg.GetChatEmotes(), command.Emote) {
+			return domain.ErrUnknownEmote
+		}
+		message.Event = domain.EventEmote
+		message.Emote = command.Emote
+	case domain.ActionChat:
+		text := strings.TrimSpace(command.Text)
+		if text == "" || utf8.RuneCountInString(text) > cfg.GetChatMaxLength() {
+			return domain.ErrChatInvalid
+		}
+		text, err := uc.filter.Filter(text)
+		if err != nil {
+			return err
+		}
+		message.Event = domain.EventChat
+		message.Text = text
+	default:
+		return domain.ErrChatInvalid
+	}
+
+	if !member.limiter.Allow() {
+		return domain.ErrChatRateLimited
+	}
+
+	return uc.ctx.Chat().Publish(ctx, member.gameId, message)
+}
+
+func isChatError(err error) bool {
+	return errors.Is(err, domain.ErrChatRateLimited) ||
+		errors.Is(err, domain.ErrChatNotSeated) ||
+		errors.Is(err, domain.ErrChatFiltered) ||
+		errors.Is(err, domain.ErrChatInvalid) ||
+		errors.Is(err, domain.ErrUnknownEmote)
+}
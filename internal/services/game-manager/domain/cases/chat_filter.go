@@ -0,0 +1,84 @@
+package cases
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+)
+
+// wordListFilter masks banned words with asterisks, ignoring case. Words are
+// told apart by Unicode letters and digits, so Cyrillic words are masked too:
+// \b of the regexp package only knows ASCII word characters.
+type wordListFilter struct {
+	pattern *regexp.Regexp
+}
+
+func NewWordListFilter(words []string) domain.ChatFilter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return wordListFilter{}
+	}
+
+	// the longest words first, so a banned word is not cut short by a banned
+	// prefix of it
+	slices.SortFunc(quoted, func(a, b string) int { return len(b) - len(a) })
+
+	return wordListFilter{
+		pattern: regexp.MustCompile(`(?i)(` + strings.Join(quoted, "|") + `)`),
+	}
+}
+
+func (f wordListFilter) Filter(text string) (string, error) {
+	if f.pattern == nil {
+		return text, nil
+	}
+
+	var filtered strings.Builder
+	last := 0
+	for start := 0; start < len(text); {
+		loc := f.pattern.FindStringIndex(text[start:])
+		if loc == nil {
+			break
+		}
+		from, to := start+loc[0], start+loc[1]
+
+		// a match inside a longer word is left alone
+		if !isWordBoundary(text, from, to) {
+			_, size := utf8.DecodeRuneInString(text[from:])
+			start = from + size
+			continue
+		}
+
+		filtered.WriteString(text[last:from])
+		filtered.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[from:to])))
+		last, start = to, to
+	}
+	filtered.WriteString(text[last:])
+
+	return filtered.String(), nil
+}
+
+// isWordBoundary reports whether text[from:to] is not part of a longer word.
+func isWordBoundary(text string, from, to int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:from]); from > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[to:]); to < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
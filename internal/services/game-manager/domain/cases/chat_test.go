@@ -0,0 +1,246 @@
+package cases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/connection"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type chatTestConfig struct {
+	testConfig
+}
+
+func (chatTestConfig) GetChatBurst() int              { return 3 }
+func (chatTestConfig) GetChatInterval() time.Duration { return time.Hour }
+func (chatTestConfig) GetChatMaxLength() int          { return 20 }
+func (chatTestConfig) GetChatEmotes() []string        { return []string{"wave"} }
+
+type seatedGames []string
+
+func (g seatedGames) Players(ctx context.Context, gameId string) ([]string, error) {
+	return g, nil
+}
+
+func (g seatedGames) Snapshot(ctx context.Context, gameId, userId string) (domain.GameSnapshot, error) {
+	return domain.GameSnapshot{}, domain.ErrNotInGame
+}
+
+func (seatedGames) ActiveGames(ctx context.Context, userId string) ([]domain.ActiveGame, error) {
+	return nil, nil
+}
+
+// lateGames fails to load the players until it seats them.
+type lateGames struct {
+	seatedGames
+	mu     sync.Mutex
+	seated bool
+}
+
+func (g *lateGames) Players(ctx context.Context, gameId string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.seated {
+		return nil, errors.New("redis is down")
+	}
+	return g.seatedGames, nil
+}
+
+func (g *lateGames) seat() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.seated = true
+}
+
+type chatClient struct {
+	member *chatMember
+	packs  chan []byte
+}
+
+func newChatTest(t *testing.T, games domain.Games) (*ChatUseCase, func(userId string) *chatClient) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	uc := NewChatUseCase(&testContext{
+		cfg:   chatTestConfig{},
+		chat:  connection.NewChat(client, 10),
+		games: games,
+	}, NewWordListFilter([]string{"darn"}))
+
+	join := func(userId string) *chatClient {
+		c := &chatClient{packs: make(chan []byte, 16)}
+		member, leave := uc.Join(context.Background(), "game", userId, func(pack []byte) error {
+			c.packs <- pack
+			return nil
+		})
+		c.member = member
+		t.Cleanup(leave)
+		return c
+	}
+
+	return uc, join
+}
+
+// next returns the messages of the next pack delivered to the client.
+func (c *chatClient) next(t *testing.T) []map[string]any {
+	t.Helper()
+
+	select {
+	case pack := <-c.packs:
+		var decoded struct {
+			Messages []map[string]any `json:"messages"`
+		}
+		if err := json.Unmarshal(pack, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded.Messages
+	case <-time.After(time.Second):
+		t.Fatal("No chat pack delivered")
+		return nil
+	}
+}
+
+func (c *chatClient) send(t *testing.T, uc *ChatUseCase, command chatCommand) []map[string]any {
+	t.Helper()
+
+	reply := uc.Handle(context.Background(), c.member, command)
+	if reply == nil {
+		return nil
+	}
+	var decoded struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	if err := json.Unmarshal(reply, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded.Messages
+}
+
+func TestChatFanOutAndFilter(t *testing.T) {
+	uc, join := newChatTest(t, seatedGames{"p1", "p2"})
+	p1, p2 := join("p1"), join("p2")
+
+	if reply := p1.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "well darn"}); reply != nil {
+		t.Fatalf("Unexpected reply %v", reply)
+	}
+
+	for _, client := range []*chatClient{p1, p2} {
+		messages := client.next(t)
+		if messages[0]["event"] != domain.EventChat || messages[0]["text"] != "well ****" || messages[0]["user_id"] != "p1" {
+			t.Errorf("Unexpected chat message %v", messages[0])
+		}
+	}
+}
+
+func TestChatMuteAndHistory(t *testing.T) {
+	uc, join := newChatTest(t, seatedGames{"p1", "p2"})
+	p1, p2 := join("p1"), join("p2")
+
+	reply := p2.send(t, uc, chatCommand{Action: domain.ActionMute, TargetId: "p1"})
+	if reply[0]["event"] != domain.EventMuted {
+		t.Fatalf("Expected MUTED, got %v", reply)
+	}
+
+	p1.send(t, uc, chatCommand{Action: domain.ActionEmote, Emote: "wave"})
+	p2.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "hi"})
+
+	if messages := p1.next(t); messages[0]["event"] != domain.EventEmote {
+		t.Errorf("Sender should see its emote, got %v", messages)
+	}
+	if messages := p2.next(t); messages[0]["user_id"] != "p2" {
+		t.Errorf("Muted player should be skipped, got %v", messages)
+	}
+
+	// a reconnecting client gets the history without muted players
+	history := join("p2").next(t)
+	if len(history) != 1 || history[0]["text"] != "hi" {
+		t.Errorf("Unexpected history %v", history)
+	}
+}
+
+func TestChatRejections(t *testing.T) {
+	uc, join := newChatTest(t, seatedGames{"p1", "p2"})
+	p1 := join("p1")
+	spectator := join("spectator")
+
+	cases := []struct {
+		client  *chatClient
+		command chatCommand
+		err     error
+	}{
+		{spectator, chatCommand{Action: domain.ActionChat, Text: "hi"}, domain.ErrChatNotSeated},
+		{p1, chatCommand{Action: domain.ActionChat, Text: "   "}, domain.ErrChatInvalid},
+		{p1, chatCommand{Action: domain.ActionChat, Text: "this message is far too long"}, domain.ErrChatInvalid},
+		{p1, chatCommand{Action: domain.ActionEmote, Emote: "dance"}, domain.ErrUnknownEmote},
+		{p1, chatCommand{Action: domain.ActionMute, TargetId: "p1"}, domain.ErrChatInvalid},
+	}
+	for _, c := range cases {
+		reply := c.client.send(t, uc, c.command)
+		if len(reply) != 1 || reply[0]["event"] != domain.EventChatRejected || reply[0]["error"] != c.err.Error() {
+			t.Errorf("%+v: expected %v, got %v", c.command, c.err, reply)
+		}
+	}
+
+	for range 3 {
+		if reply := p1.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "spam"}); reply != nil {
+			t.Fatalf("Burst should be allowed, got %v", reply)
+		}
+	}
+	reply := p1.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "spam"})
+	if len(reply) != 1 || reply[0]["error"] != domain.ErrChatRateLimited.Error() {
+		t.Errorf("Expected rate limit, got %v", reply)
+	}
+}
+
+func TestChatChecksSeatAgain(t *testing.T) {
+	games := &lateGames{seatedGames: seatedGames{"p1"}}
+	uc, join := newChatTest(t, games)
+	p1 := join("p1")
+
+	reply := p1.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "hi"})
+	if len(reply) != 1 || reply[0]["error"] != domain.ErrChatNotSeated.Error() {
+		t.Fatalf("Expected not seated while the players are unknown, got %v", reply)
+	}
+
+	games.seat()
+	if reply := p1.send(t, uc, chatCommand{Action: domain.ActionChat, Text: "hi"}); reply != nil {
+		t.Fatalf("Seated player should chat, got %v", reply)
+	}
+	if messages := p1.next(t); messages[0]["text"] != "hi" {
+		t.Errorf("Player should be subscribed once seated, got %v", messages)
+	}
+}
+
+func TestWordListFilter(t *testing.T) {
+	filter := NewWordListFilter([]string{"darn", "блин", "блинчик"})
+
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"darn it", "**** it"},
+		{"darned", "darned"},
+		{"ну блин, опять", "ну ****, опять"},
+		{"БлИн блин", "**** ****"},
+		{"блины и блинчик", "блины и *******"},
+	}
+	for _, c := range cases {
+		filtered, err := filter.Filter(c.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filtered != c.expected {
+			t.Errorf("Filter(%q) = %q, expected %q", c.text, filtered, c.expected)
+		}
+	}
+}
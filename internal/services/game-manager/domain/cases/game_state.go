@@ -0,0 +1,50 @@
+package cases
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/props"
+)
+
+type GameStateUseCase struct {
+	ctx domain.Context
+}
+
+func NewGameStateUseCase(ctx domain.Context) *GameStateUseCase {
+	return &GameStateUseCase{ctx: ctx}
+}
+
+// GetGameState reads the current state of the game for one of its players,
+// so clients can render the table before or without opening a WebSocket.
+func (uc *GameStateUseCase) GetGameState(ctx context.Context, args props.GetGameStateReq) (resp props.GetGameStateResp, err error) {
+	snapshot, err := uc.ctx.Games().Snapshot(ctx, args.GameId, args.UserId)
+	if errors.Is(err, domain.ErrGameNotFound) || errors.Is(err, domain.ErrNotInGame) {
+		return
+	}
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to read game state", "error", err.Error())
+		err = ErrInternal
+		return
+	}
+
+	resp = props.GetGameStateResp{
+		State:  snapshot.State,
+		Timers: snapshot.Timers,
+	}
+	return
+}
+
+// GetActiveGames lists the games the player can rejoin.
+func (uc *GameStateUseCase) GetActiveGames(ctx context.Context, args props.GetActiveGamesReq) (resp props.GetActiveGamesResp, err error) {
+	games, err := uc.ctx.Games().ActiveGames(ctx, args.UserId)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to read active games", "error", err.Error())
+		err = ErrInternal
+		return
+	}
+
+	resp = props.GetActiveGamesResp{Games: games}
+	return
+}
@@ -1,29 +1,42 @@
 package cases
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/props"
+	"github.com/MommusWinner/MicroDurak/lib/ratelimit"
+	"github.com/google/uuid"
 )
 
 type HandleMessageUseCase struct {
 	ctx     domain.Context
 	metrics domain.Metrics
+	chat    *ChatUseCase
+	games   *activeGames
 }
 
-func NewHandleMessageUseCase(ctx domain.Context, metrics domain.Metrics) *HandleMessageUseCase {
+func NewHandleMessageUseCase(ctx domain.Context, metrics domain.Metrics, chat *ChatUseCase) *HandleMessageUseCase {
 	return &HandleMessageUseCase{
 		ctx:     ctx,
 		metrics: metrics,
+		chat:    chat,
+		games:   newActiveGames(),
 	}
 }
 
-func (uc *HandleMessageUseCase) HandleMessage(args props.HandleMessageReq) (resp props.HandleMessageResp, err error) {
-	err = uc.ctx.Messaging().SendMessageToGame(args.Message)
+func (uc *HandleMessageUseCase) HandleMessage(ctx context.Context, args props.HandleMessageReq) (resp props.HandleMessageResp, err error) {
+	cfg := uc.ctx.Config()
+
+	start := time.Now()
+	err = uc.ctx.Messaging().SendMessageToGame(ctx, args.Message)
+	uc.metrics.ObservePublishDuration(cfg.GetPodName(), cfg.GetNamespace(), time.Since(start))
 	if err != nil {
-		uc.ctx.Logger().Error("Failed to send message to game", "error", err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to send message to game", "error", err.Error())
 		err = ErrInternal
 		return
 	}
@@ -34,52 +47,220 @@ func (uc *HandleMessageUseCase) HandleMessage(args props.HandleMessageReq) (resp
 	return
 }
 
-func (uc *HandleMessageUseCase) ConnectWebSocket(args props.ConnectWebSocketReq) error {
-	ws := args.WebSocket
+// ConnectWebSocket serves a connected client until either side closes the
+// connection, another connection of the player takes over, or ctx is
+// cancelled. The game update consumer is cancelled before it returns.
+func (uc *HandleMessageUseCase) ConnectWebSocket(ctx context.Context, args props.ConnectWebSocketReq) error {
+	cfg := uc.ctx.Config()
+
+	sessionId := uuid.NewString()
+	takeover := cfg.GetSessionPolicy() == domain.SessionPolicyTakeover
+	revoked, err := uc.ctx.Sessions().Acquire(ctx, args.GameId, args.UserId, sessionId, takeover)
+	if errors.Is(err, domain.ErrSessionActive) {
+		args.WebSocket.CloseWithCode(domain.CloseSessionActive, "session already active")
+		return nil
+	}
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to register session", "error", err.Error())
+		args.WebSocket.CloseWithCode(domain.CloseInternalError, "session registry unavailable")
+		return ErrInternal
+	}
+	defer func() {
+		if err := uc.ctx.Sessions().Release(context.Background(), args.GameId, args.UserId, sessionId); err != nil {
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to release session", "error", err.Error())
+		}
+	}()
 
-	uc.metrics.IncPlayersConnected(uc.ctx.Config().GetPodName(), uc.ctx.Config().GetNamespace())
-	defer uc.metrics.DecPlayersConnected(uc.ctx.Config().GetPodName(), uc.ctx.Config().GetNamespace())
+	uc.metrics.IncPlayersConnected(cfg.GetPodName(), cfg.GetNamespace())
+	defer uc.metrics.DecPlayersConnected(cfg.GetPodName(), cfg.GetNamespace())
 
-	endRead := make(chan bool)
-	defer close(endRead)
+	if uc.games.join(args.GameId) {
+		uc.metrics.IncActiveGames(cfg.GetPodName(), cfg.GetNamespace())
+	}
+	defer func() {
+		if uc.games.leave(args.GameId) {
+			uc.metrics.DecActiveGames(cfg.GetPodName(), cfg.GetNamespace())
+		}
+	}()
+
+	commands := newCommandTracker()
+	s := newSession(ctx, args.WebSocket, cfg.GetSendBufferSize())
+	s.sent = func(message []byte) { uc.observeSent(message, commands) }
+	s.failed = func(kind string) { uc.metrics.IncWebSocketErrors(cfg.GetPodName(), cfg.GetNamespace(), kind) }
+	defer s.cancel()
+
+	member, leaveChat := uc.chat.Join(s.ctx, args.GameId, args.UserId, s.enqueue)
+	defer leaveChat()
+
+	// a game update may seat a player who joined the chat as a spectator
+	unsubscribe, err := uc.ctx.Messaging().Subscribe(s.ctx, args.GameId, args.UserId, func(message []byte) error {
+		if !member.isSeated() {
+			uc.chat.Refresh(s.ctx, member)
+		}
+		return s.enqueue(message)
+	})
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to subscribe to game messages", "error", err.Error())
+		args.WebSocket.CloseWithCode(domain.CloseInternalError, "subscribe failed")
+		return ErrInternal
+	}
 
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if err := s.writeLoop(cfg.GetPingInterval()); err != nil {
+			uc.ctx.Logger().InfoContext(s.ctx, "Failed to write message", "error", err.Error())
+		}
+	}()
 	go func() {
-		for {
-			select {
-			case <-endRead:
+		defer wg.Done()
+		uc.readLoop(s, args, member, commands)
+	}()
+	go func() {
+		defer wg.Done()
+		uc.keepSession(s, args, sessionId, revoked)
+	}()
+
+	<-s.ctx.Done()
+	unsubscribe()
+	s.close()
+	wg.Wait()
+
+	return nil
+}
+
+// keepSession refreshes the session registration and ends the session once
+// another connection takes it over.
+func (uc *HandleMessageUseCase) keepSession(s *session, args props.ConnectWebSocketReq, sessionId string, revoked <-chan struct{}) {
+	ticker := time.NewTicker(uc.ctx.Config().GetPingInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-revoked:
+			s.stop(domain.CloseSessionReplaced, "session taken over")
+			return
+		case <-ticker.C:
+			err := uc.ctx.Sessions().Refresh(s.ctx, args.GameId, args.UserId, sessionId)
+			if errors.Is(err, domain.ErrSessionRevoked) {
+				s.stop(domain.CloseSessionReplaced, "session taken over")
 				return
-			default:
-				msg, err := ws.ReadMessage()
-				if err != nil {
-					uc.ctx.Logger().Error("Failed to read message", "error", err.Error())
-					return
-				}
-				log.Printf("ReadMessage: %v", string(msg))
-
-				_, err = uc.HandleMessage(props.HandleMessageReq{
-					GameId:  args.GameId,
-					UserId:  args.UserId,
-					Message: msg,
-				})
-				if err != nil {
-					uc.ctx.Logger().Error("Failed to handle message", "error", err.Error())
-					return
-				}
-				log.Printf("%s\n", msg)
+			}
+			if err != nil && s.ctx.Err() == nil {
+				uc.ctx.Logger().ErrorContext(s.ctx, "Failed to refresh session", "error", err.Error())
 			}
 		}
-	}()
+	}
+}
+
+// readLoop rate limits inbound commands, handles chat commands itself and
+// forwards everything else to the game.
+func (uc *HandleMessageUseCase) readLoop(s *session, args props.ConnectWebSocketReq, member *chatMember, commands *commandTracker) {
+	cfg := uc.ctx.Config()
+	guard := ratelimit.NewGuard(cfg.GetCommandBurst(), cfg.GetCommandInterval(), cfg.GetMaxViolations())
 
 	for {
-		err := uc.ctx.Messaging().ProcessQueue(args.GameId, args.UserId, func(message []byte) {
-			if err := ws.WriteMessage(message); err != nil {
-				uc.ctx.Logger().Error("Failed to write message", "error", err.Error())
+		msg, err := s.ws.ReadMessage()
+		if errors.Is(err, domain.ErrFrameTooLarge) {
+			uc.metrics.IncViolations(cfg.GetPodName(), cfg.GetNamespace(), "frame_too_large")
+			s.fail(wsErrorFrameTooLarge)
+			s.stop(domain.CloseMessageTooBig, "frame too large")
+			return
+		}
+		if err != nil {
+			if s.ctx.Err() == nil && !errors.Is(err, domain.ErrClientClosed) {
+				uc.ctx.Logger().InfoContext(s.ctx, "Connection closed", "error", err.Error())
+				if errors.Is(err, domain.ErrMalformedFrame) {
+					s.fail(wsErrorMalformed)
+				} else {
+					s.fail(wsErrorRead)
+				}
 			}
+			s.stop(domain.CloseNormalClosure, "")
+			return
+		}
+		uc.ctx.Logger().DebugContext(s.ctx, "Read message", "message", string(msg))
+
+		var inbound inboundCommand
+		json.Unmarshal(msg, &inbound)
+		uc.metrics.IncMessagesReceived(cfg.GetPodName(), cfg.GetNamespace(), inbound.messageType())
+
+		switch guard.Check() {
+		case ratelimit.Reject:
+			uc.metrics.IncViolations(cfg.GetPodName(), cfg.GetNamespace(), "rate_limited")
+			s.enqueue(commandRejected(msg))
+			continue
+		case ratelimit.Disconnect:
+			uc.metrics.IncViolations(cfg.GetPodName(), cfg.GetNamespace(), "rate_limited")
+			uc.metrics.IncAbuseDisconnects(cfg.GetPodName(), cfg.GetNamespace())
+			s.stop(domain.ClosePolicyViolation, "rate limit exceeded")
+			return
+		}
+
+		var command chatCommand
+		if json.Unmarshal(msg, &command) == nil && isChatAction(command.Action) {
+			if reply := uc.chat.Handle(s.ctx, member, command); reply != nil {
+				s.enqueue(reply)
+			}
+			continue
+		}
+
+		commands.track(inbound)
+		_, err = uc.HandleMessage(s.ctx, props.HandleMessageReq{
+			GameId:  args.GameId,
+			UserId:  args.UserId,
+			Message: msg,
 		})
 		if err != nil {
-			uc.ctx.Logger().Error("Failed to process queue", "error", err.Error())
+			s.fail(wsErrorPublish)
+			s.stop(domain.CloseInternalError, "game unavailable")
+			return
+		}
+	}
+}
+
+// observeSent counts the messages of a pack written to the client and times
+// the commands it answers.
+func (uc *HandleMessageUseCase) observeSent(pack []byte, commands *commandTracker) {
+	cfg := uc.ctx.Config()
+
+	for _, message := range parseOutbound(pack) {
+		uc.metrics.IncMessagesSent(cfg.GetPodName(), cfg.GetNamespace(), message.messageType())
+		if message.Command == nil || message.Command.CommandId == "" {
+			continue
+		}
+		if command, ok := commands.complete(message.Command.CommandId); ok {
+			uc.metrics.ObserveCommandDuration(cfg.GetPodName(), cfg.GetNamespace(), command.action, time.Since(command.sentAt))
 		}
+	}
+}
+
+// statelessPack has the shape of game packs but carries no game state.
+type statelessPack struct {
+	Messages []any `json:"messages"`
+}
 
-		time.Sleep(10 * time.Millisecond)
+type commandRejectedEvent struct {
+	Event  string `json:"event"`
+	Action string `json:"action,omitempty"`
+	Error  string `json:"error"`
+}
+
+// commandRejected builds the pack telling the client that its command was
+// dropped by the rate limit.
+func commandRejected(msg []byte) []byte {
+	var command struct {
+		Action string `json:"action"`
 	}
+	json.Unmarshal(msg, &command)
+
+	pack, _ := json.Marshal(statelessPack{Messages: []any{commandRejectedEvent{
+		Event:  domain.EventCommandRejected,
+		Action: command.Action,
+		Error:  domain.ErrCommandLimited.Error(),
+	}}})
+	return pack
 }
@@ -0,0 +1,112 @@
+package cases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+)
+
+// session owns one WebSocket connection. The reader and the writer run in
+// their own goroutines and both stop once the session context is cancelled.
+type session struct {
+	ws       domain.WebSocket
+	outbound chan []byte
+
+	// optional hooks called after a message was written and when the
+	// connection fails
+	sent   func(message []byte)
+	failed func(kind string)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	closeCode   int
+	closeReason string
+}
+
+func newSession(ctx context.Context, ws domain.WebSocket, bufferSize int) *session {
+	ctx, cancel := context.WithCancel(ctx)
+	return &session{
+		ws:       ws,
+		outbound: make(chan []byte, bufferSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		// used when the parent context ends the session, e.g. on shutdown
+		closeCode:   domain.CloseGoingAway,
+		closeReason: "server shutting down",
+	}
+}
+
+// stop ends the session. The first caller decides the close code sent to the
+// client.
+func (s *session) stop(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.closeCode = code
+	s.closeReason = reason
+	s.cancel()
+}
+
+// enqueue never blocks the consumer that delivers game updates: a client that
+// cannot keep up is disconnected and resynchronizes from the full game state
+// sent with the next pack after it reconnects.
+func (s *session) enqueue(message []byte) error {
+	select {
+	case <-s.ctx.Done():
+	case s.outbound <- message:
+	default:
+		s.fail(wsErrorSlowClient)
+		s.stop(domain.CloseTryAgainLater, "outbound buffer full")
+	}
+	return nil
+}
+
+func (s *session) fail(kind string) {
+	if s.failed != nil {
+		s.failed(kind)
+	}
+}
+
+func (s *session) writeLoop(pingInterval time.Duration) error {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case message := <-s.outbound:
+			if err := s.ws.WriteMessage(message); err != nil {
+				s.fail(wsErrorWrite)
+				s.stop(domain.CloseInternalError, "write failed")
+				return err
+			}
+			if s.sent != nil {
+				s.sent(message)
+			}
+		case <-ticker.C:
+			if err := s.ws.Ping(); err != nil {
+				s.fail(wsErrorPing)
+				s.stop(domain.CloseGoingAway, "ping failed")
+				return err
+			}
+		}
+	}
+}
+
+// close sends the close frame and closes the connection, which unblocks the
+// reader.
+func (s *session) close() error {
+	s.mu.Lock()
+	code, reason := s.closeCode, s.closeReason
+	s.mu.Unlock()
+
+	return s.ws.CloseWithCode(code, reason)
+}
@@ -0,0 +1,308 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/infra"
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/props"
+)
+
+type testConfig struct {
+	infra.Config
+	sendBufferSize int
+}
+
+func (testConfig) GetPodName() string                { return "test" }
+func (testConfig) GetNamespace() string              { return "test" }
+func (testConfig) GetPingInterval() time.Duration    { return time.Hour }
+func (testConfig) GetSessionPolicy() string          { return domain.SessionPolicyTakeover }
+func (testConfig) GetChatBurst() int                 { return 5 }
+func (testConfig) GetChatInterval() time.Duration    { return time.Second }
+func (testConfig) GetChatSpectators() bool           { return false }
+func (testConfig) GetCommandBurst() int              { return 5 }
+func (testConfig) GetCommandInterval() time.Duration { return time.Hour }
+func (testConfig) GetMaxViolations() int             { return 2 }
+func (c testConfig) GetSendBufferSize() int          { return c.sendBufferSize }
+
+type testMessaging struct {
+	mu           sync.Mutex
+	handler      func([]byte) error
+	subscribed   chan struct{}
+	unsubscribed chan struct{}
+}
+
+func (m *testMessaging) Subscribe(ctx context.Context, gameId, userId string, processMessage func([]byte) error) (func(), error) {
+	m.mu.Lock()
+	m.handler = processMessage
+	m.mu.Unlock()
+	close(m.subscribed)
+	return func() { close(m.unsubscribed) }, nil
+}
+
+func (m *testMessaging) SendMessageToGame(ctx context.Context, message []byte) error {
+	return nil
+}
+
+func (m *testMessaging) deliver(message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.handler(message)
+}
+
+type testSessions struct {
+	revoked chan struct{}
+}
+
+func (s *testSessions) Acquire(ctx context.Context, gameId, userId, sessionId string, takeover bool) (<-chan struct{}, error) {
+	return s.revoked, nil
+}
+
+func (s *testSessions) Refresh(ctx context.Context, gameId, userId, sessionId string) error {
+	return nil
+}
+
+func (s *testSessions) Release(ctx context.Context, gameId, userId, sessionId string) error {
+	return nil
+}
+
+func (s *testSessions) Close() error {
+	return nil
+}
+
+// testGames seats nobody, which keeps sessions out of the chat.
+type testGames struct{}
+
+func (testGames) Players(ctx context.Context, gameId string) ([]string, error) {
+	return nil, nil
+}
+
+func (testGames) Snapshot(ctx context.Context, gameId, userId string) (domain.GameSnapshot, error) {
+	return domain.GameSnapshot{}, domain.ErrNotInGame
+}
+
+func (testGames) ActiveGames(ctx context.Context, userId string) ([]domain.ActiveGame, error) {
+	return nil, nil
+}
+
+type testContext struct {
+	cfg       infra.Config
+	messaging *testMessaging
+	sessions  *testSessions
+	chat      domain.Chat
+	games     domain.Games
+}
+
+func (c *testContext) Make() domain.Context        { return c }
+func (c *testContext) Config() infra.Config        { return c.cfg }
+func (c *testContext) Logger() *slog.Logger        { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+func (c *testContext) Messaging() domain.Messaging { return c.messaging }
+func (c *testContext) Sessions() domain.Sessions   { return c.sessions }
+func (c *testContext) Chat() domain.Chat           { return c.chat }
+func (c *testContext) Tickets() domain.Tickets     { return nil }
+
+func (c *testContext) Games() domain.Games {
+	if c.games == nil {
+		return testGames{}
+	}
+	return c.games
+}
+
+type testMetrics struct{}
+
+func (testMetrics) IncPlayersConnected(podName, namespace string)                             {}
+func (testMetrics) DecPlayersConnected(podName, namespace string)                             {}
+func (testMetrics) IncActiveGames(podName, namespace string)                                  {}
+func (testMetrics) DecActiveGames(podName, namespace string)                                  {}
+func (testMetrics) IncViolations(podName, namespace, reason string)                           {}
+func (testMetrics) IncAbuseDisconnects(podName, namespace string)                             {}
+func (testMetrics) IncMessagesReceived(podName, namespace, messageType string)                {}
+func (testMetrics) IncMessagesSent(podName, namespace, messageType string)                    {}
+func (testMetrics) IncWebSocketErrors(podName, namespace, kind string)                        {}
+func (testMetrics) ObservePublishDuration(podName, namespace string, d time.Duration)         {}
+func (testMetrics) ObserveCommandDuration(podName, namespace, action string, d time.Duration) {}
+
+// testWebSocket blocks writes until release is closed, like a client that
+// stopped reading.
+type testWebSocket struct {
+	inbound   chan []byte
+	release   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeCode int
+}
+
+func newTestWebSocket() *testWebSocket {
+	return &testWebSocket{
+		inbound: make(chan []byte),
+		release: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (w *testWebSocket) ReadMessage() ([]byte, error) {
+	select {
+	case message := <-w.inbound:
+		return message, nil
+	case <-w.closed:
+		return nil, errors.New("closed")
+	}
+}
+
+func (w *testWebSocket) WriteMessage(message []byte) error {
+	select {
+	case <-w.release:
+		return nil
+	case <-w.closed:
+		return errors.New("closed")
+	}
+}
+
+func (w *testWebSocket) Ping() error {
+	return nil
+}
+
+func (w *testWebSocket) CloseWithCode(code int, reason string) error {
+	w.closeOnce.Do(func() {
+		w.closeCode = code
+		close(w.closed)
+	})
+	return nil
+}
+
+func (w *testWebSocket) Close() error {
+	return w.CloseWithCode(domain.CloseNormalClosure, "")
+}
+
+func connect(t *testing.T, ctx context.Context, ws *testWebSocket, bufferSize int) (*testMessaging, <-chan error) {
+	t.Helper()
+	return connectWithSessions(t, ctx, ws, bufferSize, &testSessions{})
+}
+
+func connectWithSessions(t *testing.T, ctx context.Context, ws *testWebSocket, bufferSize int, sessions *testSessions) (*testMessaging, <-chan error) {
+	t.Helper()
+
+	messaging := &testMessaging{
+		subscribed:   make(chan struct{}),
+		unsubscribed: make(chan struct{}),
+	}
+	domainCtx := &testContext{
+		cfg:       testConfig{sendBufferSize: bufferSize},
+		messaging: messaging,
+		sessions:  sessions,
+	}
+	uc := NewHandleMessageUseCase(domainCtx, testMetrics{}, NewChatUseCase(domainCtx, NewWordListFilter(nil)))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.ConnectWebSocket(ctx, props.ConnectWebSocketReq{
+			GameId:    "game",
+			UserId:    "user",
+			WebSocket: ws,
+		})
+	}()
+
+	select {
+	case <-messaging.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("Session did not subscribe to game messages")
+	}
+
+	return messaging, done
+}
+
+func waitForSessionEnd(t *testing.T, messaging *testMessaging, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Session did not end")
+	}
+
+	select {
+	case <-messaging.unsubscribed:
+	default:
+		t.Fatal("Session did not cancel its consumer")
+	}
+}
+
+func TestSessionDisconnectsSlowClient(t *testing.T) {
+	ws := newTestWebSocket()
+	messaging, done := connect(t, context.Background(), ws, 1)
+
+	// one message is stuck in the writer and one fills the buffer
+	for range 3 {
+		if err := messaging.deliver([]byte("{}")); err != nil {
+			t.Fatalf("Delivery should never fail, got %v", err)
+		}
+	}
+
+	waitForSessionEnd(t, messaging, done)
+	if ws.closeCode != domain.CloseTryAgainLater {
+		t.Errorf("Expected close code %d, got %d", domain.CloseTryAgainLater, ws.closeCode)
+	}
+}
+
+func TestSessionEndsWhenClientCloses(t *testing.T) {
+	ws := newTestWebSocket()
+	messaging, done := connect(t, context.Background(), ws, 1)
+
+	ws.Close()
+
+	waitForSessionEnd(t, messaging, done)
+}
+
+func TestSessionClosesOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ws := newTestWebSocket()
+	messaging, done := connect(t, ctx, ws, 1)
+
+	cancel()
+
+	waitForSessionEnd(t, messaging, done)
+	if ws.closeCode != domain.CloseGoingAway {
+		t.Errorf("Expected close code %d, got %d", domain.CloseGoingAway, ws.closeCode)
+	}
+}
+
+func TestSessionClosesOnTakeover(t *testing.T) {
+	sessions := &testSessions{revoked: make(chan struct{})}
+	ws := newTestWebSocket()
+	messaging, done := connectWithSessions(t, context.Background(), ws, 1, sessions)
+
+	close(sessions.revoked)
+
+	waitForSessionEnd(t, messaging, done)
+	if ws.closeCode != domain.CloseSessionReplaced {
+		t.Errorf("Expected close code %d, got %d", domain.CloseSessionReplaced, ws.closeCode)
+	}
+}
+
+func TestSessionDisconnectsAbusiveClient(t *testing.T) {
+	ws := newTestWebSocket()
+	messaging, done := connect(t, context.Background(), ws, 4)
+
+	// the burst, one rejected command and the violation that disconnects
+	for range 7 {
+		select {
+		case ws.inbound <- []byte(`{"action":"ACTION_READY"}`):
+		case <-ws.closed:
+			t.Fatal("Client was dropped too early")
+		}
+	}
+
+	waitForSessionEnd(t, messaging, done)
+	if ws.closeCode != domain.ClosePolicyViolation {
+		t.Errorf("Expected close code %d, got %d", domain.ClosePolicyViolation, ws.closeCode)
+	}
+}
@@ -0,0 +1,144 @@
+package cases
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+)
+
+// pendingCommandsLimit bounds the commands a session waits for a response
+// to. Commands the game never answers are dropped once it is reached.
+const pendingCommandsLimit = 64
+
+const (
+	messageTypeUnknown  = "unknown"
+	messageTypeResponse = "response"
+)
+
+// inboundCommand is the part of a client command used for metrics.
+type inboundCommand struct {
+	Action    string `json:"action"`
+	CommandId string `json:"command_id"`
+}
+
+// messageType keeps metric labels bounded by folding unknown actions
+// together.
+func (c inboundCommand) messageType() string {
+	switch c.Action {
+	case core.ACTION_READY, core.ACTION_ATTACK, core.ACTION_DEFEND, core.ACTION_END_ATTACK,
+		core.ACTION_TAKE_ALL_CARDS, core.ACTION_CHECK_ATTACK_TIMER, core.ACTION_CHECK_DEFEND_TIMER:
+		return c.Action
+	}
+	if isChatAction(c.Action) {
+		return c.Action
+	}
+	return messageTypeUnknown
+}
+
+// outboundMessage is the part of a pack message used for metrics: events
+// carry their name, command responses the command they answer.
+type outboundMessage struct {
+	Event   string          `json:"event"`
+	Command *inboundCommand `json:"command"`
+}
+
+func (m outboundMessage) messageType() string {
+	switch {
+	case m.Event != "":
+		return m.Event
+	case m.Command != nil:
+		return messageTypeResponse
+	}
+	return messageTypeUnknown
+}
+
+func parseOutbound(pack []byte) []outboundMessage {
+	var parsed struct {
+		Messages []outboundMessage `json:"messages"`
+	}
+	json.Unmarshal(pack, &parsed)
+	return parsed.Messages
+}
+
+type pendingCommand struct {
+	action string
+	sentAt time.Time
+}
+
+// commandTracker correlates commands with their responses by command id.
+type commandTracker struct {
+	mu      sync.Mutex
+	pending map[string]pendingCommand
+}
+
+func newCommandTracker() *commandTracker {
+	return &commandTracker{pending: make(map[string]pendingCommand)}
+}
+
+func (t *commandTracker) track(command inboundCommand) {
+	if command.CommandId == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.pending) >= pendingCommandsLimit {
+		return
+	}
+	t.pending[command.CommandId] = pendingCommand{action: command.messageType(), sentAt: time.Now()}
+}
+
+func (t *commandTracker) complete(commandId string) (pendingCommand, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	command, ok := t.pending[commandId]
+	delete(t.pending, commandId)
+	return command, ok
+}
+
+// activeGames counts the sessions per game on this pod.
+type activeGames struct {
+	mu       sync.Mutex
+	sessions map[string]int
+}
+
+func newActiveGames() *activeGames {
+	return &activeGames{sessions: make(map[string]int)}
+}
+
+// join reports whether the session is the first of the game on this pod.
+func (g *activeGames) join(gameId string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sessions[gameId]++
+	return g.sessions[gameId] == 1
+}
+
+// leave reports whether the session was the last of the game on this pod.
+func (g *activeGames) leave(gameId string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sessions[gameId]--
+	if g.sessions[gameId] > 0 {
+		return false
+	}
+	delete(g.sessions, gameId)
+	return true
+}
+
+// Kinds of WebSocket errors reported to metrics.
+const (
+	wsErrorRead          = "read"
+	wsErrorMalformed     = "malformed"
+	wsErrorFrameTooLarge = "frame_too_large"
+	wsErrorWrite         = "write"
+	wsErrorPing          = "ping"
+	wsErrorSlowClient    = "slow_client"
+	wsErrorPublish       = "publish"
+)
@@ -0,0 +1,60 @@
+package cases
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+)
+
+func TestCommandTrackerMatchesResponse(t *testing.T) {
+	game, err := core.CreateNewGame([]string{"first", "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, _ := json.Marshal(core.Command{GameId: game.Id, Action: core.ACTION_READY, UserId: "first", CommandId: "command"})
+	var inbound inboundCommand
+	json.Unmarshal(message, &inbound)
+
+	commands := newCommandTracker()
+	commands.track(inbound)
+
+	packs, err := game.HandleMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outbound := parseOutbound(packs["first"])
+	if len(outbound) != 2 || outbound[0].messageType() != messageTypeResponse || outbound[1].messageType() != core.EVENT_READY {
+		t.Fatalf("Unexpected outbound messages: %+v", outbound)
+	}
+
+	command, ok := commands.complete(outbound[0].Command.CommandId)
+	if !ok || command.action != core.ACTION_READY {
+		t.Fatalf("Response should complete the ready command, got %+v, %v", command, ok)
+	}
+	if _, ok := commands.complete("command"); ok {
+		t.Error("A command should complete only once")
+	}
+}
+
+func TestInboundMessageType(t *testing.T) {
+	if (inboundCommand{Action: "ACTION_WHATEVER"}).messageType() != messageTypeUnknown {
+		t.Error("Unknown actions should share one label")
+	}
+	if (inboundCommand{Action: core.ACTION_ATTACK}).messageType() != core.ACTION_ATTACK {
+		t.Error("Game actions should keep their label")
+	}
+}
+
+func TestActiveGames(t *testing.T) {
+	games := newActiveGames()
+
+	if !games.join("game") || games.join("game") {
+		t.Error("Only the first session should activate the game")
+	}
+	if games.leave("game") || !games.leave("game") {
+		t.Error("Only the last session should deactivate the game")
+	}
+}
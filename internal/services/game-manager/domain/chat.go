@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Chat actions are handled by the game-manager and never reach the game.
+const (
+	ActionChat   = "ACTION_CHAT"
+	ActionEmote  = "ACTION_EMOTE"
+	ActionMute   = "ACTION_MUTE"
+	ActionUnmute = "ACTION_UNMUTE"
+)
+
+const (
+	EventChat         = "CHAT"
+	EventEmote        = "EMOTE"
+	EventMuted        = "MUTED"
+	EventUnmuted      = "UNMUTED"
+	EventChatRejected = "CHAT_REJECTED"
+)
+
+var (
+	ErrChatRateLimited = errors.New("ERROR_RATE_LIMITED")
+	ErrChatNotSeated   = errors.New("ERROR_NOT_SEATED")
+	ErrChatFiltered    = errors.New("ERROR_MESSAGE_FILTERED")
+	ErrChatInvalid     = errors.New("ERROR_INVALID_MESSAGE")
+	ErrUnknownEmote    = errors.New("ERROR_UNKNOWN_EMOTE")
+	ErrChatUnavailable = errors.New("ERROR_CHAT_UNAVAILABLE")
+)
+
+type ChatMessage struct {
+	Event  string    `json:"event"`
+	UserId string    `json:"user_id"`
+	Text   string    `json:"text,omitempty"`
+	Emote  string    `json:"emote,omitempty"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Chat fans chat messages of a game out to every game-manager pod and keeps
+// the recent history and the mute lists of the game.
+type Chat interface {
+	Publish(ctx context.Context, gameId string, message ChatMessage) error
+	Subscribe(gameId string, handler func(ChatMessage)) (unsubscribe func(), err error)
+	History(ctx context.Context, gameId string) ([]ChatMessage, error)
+	Muted(ctx context.Context, gameId string, userId string) ([]string, error)
+	SetMuted(ctx context.Context, gameId string, userId string, targetId string, muted bool) error
+	// Close stops the chat subscriptions.
+	Close() error
+}
+
+// ChatFilter is the profanity filter hook. It returns the text to deliver,
+// or ErrChatFiltered to drop the message.
+type ChatFilter interface {
+	Filter(text string) (string, error)
+}
@@ -11,4 +11,8 @@ type Context interface {
 	Config() infra.Config
 	Logger() *slog.Logger
 	Messaging() Messaging
+	Sessions() Sessions
+	Chat() Chat
+	Games() Games
+	Tickets() Tickets
 }
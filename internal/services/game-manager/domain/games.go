@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+)
+
+var (
+	ErrGameNotFound = errors.New("game not found")
+	ErrNotInGame    = errors.New("player is not in the game")
+)
+
+// GameSnapshot is a game as seen by one of its players.
+type GameSnapshot struct {
+	State  core.GameStateResponse
+	Timers core.TimersResponse
+}
+
+// ActiveGame is a game the player is seated in that has not ended yet.
+type ActiveGame struct {
+	GameId string
+	Status string
+}
+
+// Games reads the games stored by the game service.
+type Games interface {
+	Players(ctx context.Context, gameId string) ([]string, error)
+	// Snapshot returns ErrGameNotFound for unknown games and ErrNotInGame
+	// when the player has no seat in the game.
+	Snapshot(ctx context.Context, gameId, userId string) (GameSnapshot, error)
+	ActiveGames(ctx context.Context, userId string) ([]ActiveGame, error)
+}
@@ -1,10 +1,35 @@
 package infra
 
+import (
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
+)
+
 type Config interface {
 	GetJWTPublic() string
 	GetRabbitmqURL() string
+	GetRedisURL() string
 	GetPort() string
 	GetPodName() string
 	GetNamespace() string
 	GetLogLevel() string
+	GetTracing() tracing.Config
+	GetPingInterval() time.Duration
+	GetPongWait() time.Duration
+	GetWriteWait() time.Duration
+	GetSendBufferSize() int
+	GetAllowedOrigins() []string
+	GetMaxFrameSize() int
+	GetCommandBurst() int
+	GetCommandInterval() time.Duration
+	GetMaxViolations() int
+	GetSessionPolicy() string
+	GetChatHistorySize() int
+	GetChatMaxLength() int
+	GetChatBurst() int
+	GetChatInterval() time.Duration
+	GetChatSpectators() bool
+	GetChatEmotes() []string
+	GetChatBannedWords() []string
 }
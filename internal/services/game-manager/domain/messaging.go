@@ -1,6 +1,10 @@
 package domain
 
+import "context"
+
 type Messaging interface {
-	ProcessQueue(gameId string, userId string, processMessage func([]byte)) error
-	SendMessageToGame(message []byte) error
+	// Subscribe delivers messages addressed to the player of the game until
+	// the returned unsubscribe function is called.
+	Subscribe(ctx context.Context, gameId string, userId string, processMessage func([]byte) error) (unsubscribe func(), err error)
+	SendMessageToGame(ctx context.Context, message []byte) error
 }
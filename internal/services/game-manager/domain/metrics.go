@@ -1,6 +1,17 @@
 package domain
 
+import "time"
+
 type Metrics interface {
 	IncPlayersConnected(podName, namespace string)
 	DecPlayersConnected(podName, namespace string)
+	IncActiveGames(podName, namespace string)
+	DecActiveGames(podName, namespace string)
+	IncViolations(podName, namespace, reason string)
+	IncAbuseDisconnects(podName, namespace string)
+	IncMessagesReceived(podName, namespace, messageType string)
+	IncMessagesSent(podName, namespace, messageType string)
+	IncWebSocketErrors(podName, namespace, kind string)
+	ObservePublishDuration(podName, namespace string, duration time.Duration)
+	ObserveCommandDuration(podName, namespace, action string, duration time.Duration)
 }
@@ -0,0 +1,24 @@
+package props
+
+import (
+	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+)
+
+type GetGameStateReq struct {
+	GameId string
+	UserId string
+}
+
+type GetGameStateResp struct {
+	State  core.GameStateResponse
+	Timers core.TimersResponse
+}
+
+type GetActiveGamesReq struct {
+	UserId string
+}
+
+type GetActiveGamesResp struct {
+	Games []domain.ActiveGame
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// Policies for a second connection of a player to the same game.
+const (
+	SessionPolicyReject   = "reject"
+	SessionPolicyTakeover = "takeover"
+)
+
+var (
+	ErrSessionActive  = errors.New("session already active")
+	ErrSessionRevoked = errors.New("session taken over")
+)
+
+// Sessions tracks the single active WebSocket session of a player in a game
+// across all game-manager pods.
+type Sessions interface {
+	// Acquire registers sessionId as the active session. With takeover the
+	// previous session is revoked, otherwise ErrSessionActive is returned
+	// while it is alive. The returned channel is closed when sessionId itself
+	// is taken over.
+	Acquire(ctx context.Context, gameId string, userId string, sessionId string, takeover bool) (revoked <-chan struct{}, err error)
+	// Refresh keeps the registration alive and returns ErrSessionRevoked once
+	// another session owns it.
+	Refresh(ctx context.Context, gameId string, userId string, sessionId string) error
+	Release(ctx context.Context, gameId string, userId string, sessionId string) error
+	// Close stops listening for takeovers.
+	Close() error
+}
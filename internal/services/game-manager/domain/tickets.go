@@ -0,0 +1,8 @@
+package domain
+
+import "context"
+
+// Tickets redeems the single-use tickets issued by the auth service.
+type Tickets interface {
+	Consume(ctx context.Context, ticket, scope string) (playerId string, err error)
+}
@@ -1,7 +1,45 @@
 package domain
 
+import "errors"
+
+// Close codes sent to the client when the server ends a session (RFC 6455).
+const (
+	CloseNormalClosure   = 1000
+	CloseGoingAway       = 1001
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+
+	// application codes
+	CloseSessionReplaced = 4001
+	CloseSessionActive   = 4002
+)
+
+const (
+	EventCommandRejected = "COMMAND_REJECTED"
+)
+
+var (
+	// ErrFrameTooLarge is returned by ReadMessage for frames over the limit.
+	ErrFrameTooLarge = errors.New("frame too large")
+	// ErrMalformedFrame is returned by ReadMessage for frames that cannot be
+	// decoded.
+	ErrMalformedFrame = errors.New("malformed frame")
+	// ErrClientClosed is returned by ReadMessage once the client closed the
+	// connection cleanly.
+	ErrClientClosed   = errors.New("client closed the connection")
+	ErrCommandLimited = errors.New("ERROR_RATE_LIMITED")
+)
+
 type WebSocket interface {
 	ReadMessage() (message []byte, err error)
 	WriteMessage(message []byte) error
+	// Ping sends a heartbeat; the read deadline is extended when the pong
+	// arrives.
+	Ping() error
+	// CloseWithCode sends a close frame and closes the connection, which
+	// unblocks a pending ReadMessage.
+	CloseWithCode(code int, reason string) error
 	Close() error
 }
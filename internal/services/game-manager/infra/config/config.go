@@ -1,8 +1,12 @@
 package config
 
 import (
-	"github.com/alecthomas/kong"
 	"log"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/infra"
 )
@@ -10,10 +14,32 @@ import (
 type config struct {
 	JWTPublic   string `help:"Base64 Private key for the jwt"       env:"JWT_PUBLIC"   required:"true"`
 	RabbitmqURL string `help:"Rabbitmq connection URL"              env:"RABBITMQ_URL" required:"true"`
+	RedisURL    string `help:"Redis connection URL"                 env:"REDIS_URL"    required:"true"`
 	Port        string `help:"Port to listen on"                    env:"PORT"                         default:"7070"`
 	PodName     string `help:"K8s pod name" env:"POD_NAME" default:"unknown"`
 	Namespace   string `help:"K8s namespace" env:"NAMESPACE" default:"unknown"`
 	LogLevel    string `help:"Log level (debug, info, warn, error)" env:"LOG_LEVEL"                    default:"info"`
+
+	PingInterval    time.Duration `help:"Interval between WebSocket pings"                    env:"WS_PING_INTERVAL"      default:"25s"`
+	PongWait        time.Duration `help:"Time to wait for a pong before dropping the client"  env:"WS_PONG_WAIT"          default:"60s"`
+	WriteWait       time.Duration `help:"Time allowed to write a WebSocket frame"             env:"WS_WRITE_WAIT"         default:"10s"`
+	SendBufferSize  int           `help:"Outbound messages buffered per WebSocket session"    env:"WS_SEND_BUFFER_SIZE"   default:"32"`
+	AllowedOrigins  []string      `help:"Origins allowed to open WebSockets, * for any"      env:"WS_ALLOWED_ORIGINS"`
+	MaxFrameSize    int           `help:"Largest inbound WebSocket frame in bytes"           env:"WS_MAX_FRAME_SIZE"     default:"4096"`
+	CommandBurst    int           `help:"Commands a player may send in a burst"              env:"WS_COMMAND_BURST"      default:"10"`
+	CommandInterval time.Duration `help:"Time to regain one command of the burst"            env:"WS_COMMAND_INTERVAL"   default:"100ms"`
+	MaxViolations   int           `help:"Rate limit violations before the client is dropped" env:"WS_MAX_VIOLATIONS"     default:"5"`
+	SessionPolicy   string        `help:"What a second connection of a player to a game does" env:"SESSION_POLICY"        default:"takeover" enum:"reject,takeover"`
+
+	ChatHistorySize int           `help:"Chat messages kept per game for reconnecting clients" env:"CHAT_HISTORY_SIZE"  default:"50"`
+	ChatMaxLength   int           `help:"Maximum length of a chat message"                     env:"CHAT_MAX_LENGTH"    default:"200"`
+	ChatBurst       int           `help:"Chat messages a player may send in a burst"           env:"CHAT_BURST"         default:"5"`
+	ChatInterval    time.Duration `help:"Time to regain one chat message of the burst"         env:"CHAT_INTERVAL"      default:"2s"`
+	ChatSpectators  bool          `help:"Let players who are not seated read the chat"         env:"CHAT_SPECTATORS"    default:"false"`
+	ChatEmotes      []string      `help:"Emotes players may send"                              env:"CHAT_EMOTES"        default:"thumbs_up,laugh,cry,angry,wave,clap"`
+	ChatBannedWords []string      `help:"Words masked in chat messages"                        env:"CHAT_BANNED_WORDS"`
+
+	Tracing tracing.Config `embed:""`
 }
 
 func Make() infra.Config {
@@ -40,6 +66,10 @@ func (s *config) GetRabbitmqURL() string {
 	return s.RabbitmqURL
 }
 
+func (s *config) GetRedisURL() string {
+	return s.RedisURL
+}
+
 func (s *config) GetPort() string {
 	return s.Port
 }
@@ -55,3 +85,75 @@ func (s *config) GetNamespace() string {
 func (s *config) GetLogLevel() string {
 	return s.LogLevel
 }
+
+func (s *config) GetTracing() tracing.Config {
+	return s.Tracing
+}
+
+func (s *config) GetPingInterval() time.Duration {
+	return s.PingInterval
+}
+
+func (s *config) GetPongWait() time.Duration {
+	return s.PongWait
+}
+
+func (s *config) GetWriteWait() time.Duration {
+	return s.WriteWait
+}
+
+func (s *config) GetSendBufferSize() int {
+	return s.SendBufferSize
+}
+
+func (s *config) GetAllowedOrigins() []string {
+	return s.AllowedOrigins
+}
+
+func (s *config) GetMaxFrameSize() int {
+	return s.MaxFrameSize
+}
+
+func (s *config) GetCommandBurst() int {
+	return s.CommandBurst
+}
+
+func (s *config) GetCommandInterval() time.Duration {
+	return s.CommandInterval
+}
+
+func (s *config) GetMaxViolations() int {
+	return s.MaxViolations
+}
+
+func (s *config) GetSessionPolicy() string {
+	return s.SessionPolicy
+}
+
+func (s *config) GetChatHistorySize() int {
+	return s.ChatHistorySize
+}
+
+func (s *config) GetChatMaxLength() int {
+	return s.ChatMaxLength
+}
+
+func (s *config) GetChatBurst() int {
+	return s.ChatBurst
+}
+
+func (s *config) GetChatInterval() time.Duration {
+	return s.ChatInterval
+}
+
+func (s *config) GetChatSpectators() bool {
+	return s.ChatSpectators
+}
+
+func (s *config) GetChatEmotes() []string {
+	return s.ChatEmotes
+}
+
+func (s *config) GetChatBannedWords() []string {
+	return s.ChatBannedWords
+}
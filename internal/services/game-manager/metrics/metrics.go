@@ -13,4 +13,70 @@ var (
 		},
 		[]string{"pod", "namespace"},
 	)
+
+	ActiveGames = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "game_manager_active_games",
+			Help: "Current games with at least one player connected to this pod",
+		},
+		[]string{"pod", "namespace"},
+	)
+
+	MessagesReceived = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_manager_messages_received_total",
+			Help: "Total number of messages received from clients by action",
+		},
+		[]string{"pod", "namespace", "type"},
+	)
+
+	MessagesSent = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_manager_messages_sent_total",
+			Help: "Total number of messages sent to clients by event",
+		},
+		[]string{"pod", "namespace", "type"},
+	)
+
+	WebSocketErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_manager_websocket_errors_total",
+			Help: "Total number of WebSocket connection errors by kind",
+		},
+		[]string{"pod", "namespace", "kind"},
+	)
+
+	PublishDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "game_manager_publish_duration_seconds",
+			Help:    "Time to publish a command to the game service",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+		},
+		[]string{"pod", "namespace"},
+	)
+
+	CommandDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "game_manager_command_duration_seconds",
+			Help:    "Time from reading a command to writing its response, by action",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{"pod", "namespace", "action"},
+	)
+
+	Violations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_manager_websocket_violations_total",
+			Help: "Total number of inbound WebSocket messages over the rate or size limit",
+		},
+		[]string{"pod", "namespace", "reason"},
+	)
+
+	AbuseDisconnects = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_manager_websocket_abuse_disconnects_total",
+			Help: "Total number of clients disconnected after repeated violations",
+		},
+		[]string{"pod", "namespace"},
+	)
 )
@@ -1,51 +1,108 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	pb "github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/game/config"
 	"github.com/MommusWinner/MicroDurak/internal/services/game/controller"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
 	gameGrpc "github.com/MommusWinner/MicroDurak/internal/services/game/grpc"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/metrics"
+	"github.com/MommusWinner/MicroDurak/lib/amqppool"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
+	"github.com/MommusWinner/MicroDurak/lib/transport"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 )
 
+// activeGamesSweepInterval is how often expired games leave the active games.
+const activeGamesSweepInterval = time.Minute
+
 func run(grpcServer *grpc.Server) error {
 	conf, err := config.Load()
 	if err != nil {
 		return err
 	}
 
+	logger, err := logging.New("game", conf.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "game", conf.Tracing)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
 	opt, err := redis.ParseURL(conf.RedisURL)
 	if err != nil {
 		return err
 	}
 
 	client := redis.NewClient(opt)
-	channel, err := connectToRabbit(conf)
-
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return err
+	}
+	conn, err := amqppool.Dial("game", conf.RabbitmqURL)
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
-	errChan := make(chan error, 2)
+	metrics.RegisterActiveGames(func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		count, err := core.CountActiveGames(ctx, client)
+		if err != nil {
+			logger.Warn("Failed to count active games", "error", err)
+		}
+		return float64(count)
+	})
+	metricsServer := &http.Server{Addr: ":" + conf.GameServicePort, Handler: promhttp.Handler()}
+
+	errChan := make(chan error, 3)
+
+	rabbitTransport, err := transport.NewRabbitMQ(conn)
+	if err != nil {
+		return err
+	}
 
-	gameController := controller.NewGameController(conf, channel, client)
+	gameController := controller.NewGameController(conf, rabbitTransport, client, logger)
 	pb.RegisterGameServer(grpcServer, gameGrpc.NewGameServer(&gameController, conf))
 
 	go func() {
 		errChan <- startGrpc(grpcServer, conf)
 	}()
 
+	go func() {
+		logger.Info("Starting metrics server", "port", conf.GameServicePort)
+		if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errChan <- fmt.Errorf("metrics server error: %w", err)
+		}
+	}()
+
 	go gameController.ProcessQueues()
 
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	go sweepActiveGames(sweepCtx, client, logger)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -53,16 +110,41 @@ func run(grpcServer *grpc.Server) error {
 	case err := <-errChan:
 		return err
 	case <-quit:
-		log.Println("\nShutting down servers...")
+		logger.Info("Shutting down servers...")
 
 		grpcServer.GracefulStop()
-		fmt.Println("Servers stopped successfully")
+		metricsServer.Close()
+		logger.Info("Servers stopped successfully")
 		return nil
 	}
 }
 
+// sweepActiveGames periodically drops the games that expired without ending
+// from the active games.
+func sweepActiveGames(ctx context.Context, client *redis.Client, logger *slog.Logger) {
+	ticker := time.NewTicker(activeGamesSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		removed, err := core.SweepActiveGames(ctx, client)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to sweep active games", "error", err)
+			continue
+		}
+		if removed > 0 {
+			logger.InfoContext(ctx, "Swept expired active games", "count", removed)
+		}
+	}
+}
+
 func startGrpc(grpcServer *grpc.Server, conf *config.Config) error {
-	log.Printf("Starting gRPC server on :%s\n", conf.GRPCPort)
+	slog.Info("Starting gRPC server", "port", conf.GRPCPort)
 	lis, err := net.Listen("tcp", ":"+conf.GRPCPort)
 	if err != nil {
 		return fmt.Errorf("gRPC listen error: %w", err)
@@ -74,21 +156,8 @@ func startGrpc(grpcServer *grpc.Server, conf *config.Config) error {
 	return nil
 }
 
-func connectToRabbit(conf *config.Config) (*amqp.Channel, error) {
-	conn, err := amqp.Dial(conf.RabbitmqURL)
-	if err != nil {
-		return nil, err
-	}
-	channel, err := conn.Channel()
-	if err != nil {
-		return nil, err
-	}
-
-	return channel, err
-}
-
 func main() {
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(tracing.ServerOption())
 
 	if err := run(grpcServer); err != nil {
 		panic(err)
@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/alecthomas/kong"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 )
 
 type Config struct {
@@ -10,6 +12,8 @@ type Config struct {
 	GameServicePort string `help:"Port to listen on"                    env:"GAME_SERVICE_PORT"                 default:"7077"`
 	GRPCPort        string `help:"Port to listen on"                    env:"GRPC_PORT"         required:"true" default:"9090"`
 	LogLevel        string `help:"Log level (debug, info, warn, error)" env:"LOG_LEVEL"                         default:"info"`
+
+	Tracing tracing.Config `embed:""`
 }
 
 func Load() (*Config, error) {
@@ -3,177 +3,150 @@ package controller
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/game/config"
 	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/metrics"
+	"github.com/MommusWinner/MicroDurak/lib/transport"
 	"github.com/redis/go-redis/v9"
 )
 
 type GameController struct {
-	Config  *config.Config
-	Channel *amqp.Channel
-	Redis   *redis.Client
+	Config    *config.Config
+	Transport transport.Transport
+	Redis     *redis.Client
+	Logger    *slog.Logger
 }
 
 func NewGameController(
 	conf *config.Config,
-	channel *amqp.Channel,
+	transport transport.Transport,
 	redis *redis.Client,
+	logger *slog.Logger,
 ) GameController {
 	return GameController{
-		Config:  conf,
-		Channel: channel,
-		Redis:   redis,
+		Config:    conf,
+		Transport: transport,
+		Redis:     redis,
+		Logger:    logger,
 	}
 }
 
-func (gc GameController) CreateGame(userIds []string) (*core.Game, error) {
-	return core.CreateNewGameAndSaveInRedis(gc.Redis, userIds)
+func (gc GameController) CreateGame(ctx context.Context, userIds []string, settings core.GameSettings) (*core.Game, error) {
+	return core.CreateNewGameAndSaveInRedis(ctx, gc.Redis, userIds, settings)
 }
 
-func (gc GameController) LoadGame(gameId string) (*core.Game, error) {
-	return core.LoadGame(gc.Redis, gameId)
+func (gc GameController) LoadGame(ctx context.Context, gameId string) (*core.Game, error) {
+	return core.LoadGame(ctx, gc.Redis, gameId)
 }
 
 func (gc GameController) ProcessQueues() {
-	gc.processQueue(func(message []byte) {
+	gc.Transport.ConsumeCommands(func(ctx context.Context, message []byte) error {
 		var command core.Command
-		json.Unmarshal(message, &command)
-		game, err := core.LoadGame(gc.Redis, command.GameId)
-		if err != nil {
-			log.Fatalf("Room with id %s does not exist", command.GameId)
-			return
+		if err := json.Unmarshal(message, &command); err != nil {
+			return fmt.Errorf("%w: %v", transport.ErrPoisonMessage, err)
 		}
 
-		messageByUser, err := game.HandleMessage(message)
+		start := time.Now()
+		game, err := core.LoadGame(ctx, gc.Redis, command.GameId)
+		metrics.Duration.WithLabelValues("load").Observe(time.Since(start).Seconds())
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%w: room with id %s does not exist", transport.ErrPoisonMessage, command.GameId)
+		}
 		if err != nil {
-			log.Fatalf("Error occurred while processing a messag. Room id: %s", command.GameId)
-			return
+			return err
 		}
 
-		for userId, userMessage := range messageByUser {
+		if game.HasHandledCommand(command.CommandId) {
+			gc.Logger.InfoContext(ctx, "Answer already handled command", "command_id", command.CommandId, "game_id", command.GameId)
+			pack, err := game.DuplicatePack(command)
+			if err != nil {
+				return fmt.Errorf("%w: %v", transport.ErrPoisonMessage, err)
+			}
+			// the duplicate answers only its user, redelivering it is safe
+			return gc.sendUpdate(ctx, game.Id, command.UserId, pack)
+		}
 
-			log.Printf("Get message by %s", userId)
-			log.Printf("Message:  %s", userMessage)
-			gc.SendMessageToGameManager(game.Id, userId, userMessage)
+		start = time.Now()
+		result, err := game.HandleCommand(message)
+		metrics.Duration.WithLabelValues("handle").Observe(time.Since(start).Seconds())
+		if err != nil {
+			return fmt.Errorf("%w: %v", transport.ErrPoisonMessage, err)
 		}
-		core.SaveGame(game, gc.Redis)
-	})
-}
 
-func (gc GameController) processQueue(processMessage func([]byte)) {
-	queue_name := "game"
-	exchange_name := "gameEx"
-
-	_, err := gc.Channel.QueueDeclare(
-		queue_name, // name
-		false,      // durable
-		false,      // delete when unused
-		false,      // exclusive
-		false,      // no-wait
-		nil,        // arguments
-	)
-	if err != nil {
-		log.Printf("Declare err: %v", err)
-		panic(err)
-	}
-	err = gc.Channel.ExchangeDeclare(
-		exchange_name, // name
-		"direct",      // type
-		true,          // durable
-		false,         // auto-deleted
-		false,         // internal
-		false,         // no-wait
-		nil,           // arguments
-	)
-	if err != nil {
-		log.Printf("Exchange err: %v", err)
-		panic(err)
-	}
-	msgs, _ := gc.Channel.Consume(
-		queue_name, // queue
-		"",         // consumer
-		true,       // auto-ack
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
-	)
-
-	func() {
-		for d := range msgs {
-			processMessage(d.Body)
+		start = time.Now()
+		err = core.SaveGame(ctx, game, gc.Redis)
+		metrics.Duration.WithLabelValues("save").Observe(time.Since(start).Seconds())
+		if err != nil {
+			return err
+		}
+
+		observeCommand(result, game)
+		gc.Logger.DebugContext(ctx, "Handled command",
+			"game_id", game.Id,
+			"user_id", command.UserId,
+			"action", command.Action,
+			"error", result.Error,
+			"event_seq", game.EventSeq,
+		)
+
+		// the game is saved, a redelivery would only answer the user of the
+		// command, so updates that failed are not retried
+		for userId, userMessage := range result.Packs {
+			gc.sendUpdate(ctx, game.Id, userId, userMessage)
 		}
-	}()
+		return nil
+	})
 }
 
+// SendMessageToGameManager continues the trace of ctx but not its
+// cancellation, so that updates of a handled command are always sent.
 func (gc GameController) SendMessageToGameManager(
+	ctx context.Context,
 	gameId string,
 	userId string,
 	message []byte,
 ) error {
-	queue_name := "game-manager-" + userId + "_" + gameId
-	exchange_name := "game-manager-ex"
-
-	_, err := gc.Channel.QueueDeclare(
-		queue_name, // name
-		false,      // durable
-		false,      // delete when unused
-		false,      // exclusive
-		false,      // no-wait
-		nil,        // arguments
-	)
-	if err != nil {
-		log.Print(err)
-		return err
-	}
-
-	err = gc.Channel.ExchangeDeclare(
-		exchange_name, // name
-		"direct",      // type
-		true,          // durable
-		false,         // auto-deleted
-		false,         // internal
-		false,         // no-wait
-		nil,           // arguments
-	)
-	if err != nil {
-		log.Print(err)
-		return err
-	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	defer cancel()
 
-	err = gc.Channel.QueueBind(
-		queue_name,    // queue name
-		queue_name,    // routing key
-		exchange_name, // exchange
-		false,
-		nil,
-	)
+	return gc.Transport.SendUpdate(ctx, gameId, userId, message)
+}
 
+// sendUpdate sends the update to game-manager, logging and counting a
+// failure.
+func (gc GameController) sendUpdate(ctx context.Context, gameId string, userId string, message []byte) error {
+	err := gc.SendMessageToGameManager(ctx, gameId, userId, message)
 	if err != nil {
-		log.Print(err)
-		return err
+		metrics.UpdatesFailed.Inc()
+		gc.Logger.ErrorContext(ctx, "Failed to send update", "game_id", gameId, "user_id", userId, "error", err.Error())
 	}
+	return err
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	err = gc.Channel.PublishWithContext(ctx,
-		exchange_name, // exChannelange
-		queue_name,    // routing key
-		false,         // mandatory
-		false,         // immediate
-		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        message,
-		})
-	if err != nil {
-		log.Print(err)
-		return err
+// observeCommand records the outcome of a command once the game is saved.
+func observeCommand(result core.CommandResult, game *core.Game) {
+	action, gameError := result.Action, result.Error
+	if gameError == core.ERROR_UNREGISTERED_ACTION {
+		// keep the label set bounded
+		action = "unknown"
 	}
+	if gameError == core.ERROR_EMPTY {
+		gameError = "none"
+	}
+	metrics.Commands.WithLabelValues(action, gameError).Inc()
 
-	return err
+	if result.Started {
+		metrics.GamesStarted.Inc()
+	}
+	if result.Ended {
+		metrics.GamesFinished.WithLabelValues(string(game.Result)).Inc()
+	}
+	if result.ExpiredTimer != "" {
+		metrics.TimersExpired.WithLabelValues(result.ExpiredTimer).Inc()
+	}
 }
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	GameStatusWaiting    = "waiting"
+	GameStatusInProgress = "in_progress"
+	GameStatusEnded      = "ended"
+)
+
+// abandonedGameTTL drops games that were abandoned without ending, along
+// with the index of their players. Every save of a game extends it.
+const abandonedGameTTL = 24 * time.Hour
+
+// activeGamesKey holds the ids of all games that have not ended. Ids of
+// abandoned games stay in it until SweepActiveGames finds their game gone.
+const activeGamesKey = "games:active"
+
+// sweepBatchSize is the number of active game ids checked at once.
+const sweepBatchSize = 100
+
+func playerGamesKey(playerId string) string {
+	return "player-games:" + playerId
+}
+
+func (g *Game) Status() string {
+	switch {
+	case g.IsEnded:
+		return GameStatusEnded
+	case g.IsStarted:
+		return GameStatusInProgress
+	default:
+		return GameStatusWaiting
+	}
+}
+
+// storeGame saves the game and keeps the active games of its players in sync
+// with it.
+func storeGame(ctx context.Context, client *redis.Client, game *Game, value []byte) error {
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if game.IsEnded {
+			pipe.Set(ctx, "game:"+game.Id, value, 0)
+		} else {
+			pipe.Set(ctx, "game:"+game.Id, value, abandonedGameTTL)
+		}
+		if game.IsEnded {
+			pipe.SRem(ctx, activeGamesKey, game.Id)
+		} else {
+			pipe.SAdd(ctx, activeGamesKey, game.Id)
+		}
+		for _, user := range game.Users {
+			if game.IsEnded {
+				pipe.SRem(ctx, playerGamesKey(user.Id), game.Id)
+			} else {
+				pipe.SAdd(ctx, playerGamesKey(user.Id), game.Id)
+				pipe.Expire(ctx, playerGamesKey(user.Id), abandonedGameTTL)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// ActiveGameIds returns the ids of the games the player is seated in and
+// that have not ended yet.
+func ActiveGameIds(ctx context.Context, redis *redis.Client, playerId string) ([]string, error) {
+	return redis.SMembers(ctx, playerGamesKey(playerId)).Result()
+}
+
+// CountActiveGames returns the number of games that have not ended yet.
+func CountActiveGames(ctx context.Context, redis *redis.Client) (int64, error) {
+	return redis.SCard(ctx, activeGamesKey).Result()
+}
+
+// SweepActiveGames removes the ids of games that expired without ending from
+// the active games and returns how many it removed.
+func SweepActiveGames(ctx context.Context, client *redis.Client) (int64, error) {
+	var removed int64
+	var cursor uint64
+	for {
+		ids, next, err := client.SScan(ctx, activeGamesKey, cursor, "", sweepBatchSize).Result()
+		if err != nil {
+			return removed, err
+		}
+
+		exists := make([]*redis.IntCmd, len(ids))
+		_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, id := range ids {
+				exists[i] = pipe.Exists(ctx, "game:"+id)
+			}
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		var expired []any
+		for i, id := range ids {
+			if exists[i].Val() == 0 {
+				expired = append(expired, id)
+			}
+		}
+		if len(expired) > 0 {
+			count, err := client.SRem(ctx, activeGamesKey, expired...).Result()
+			if err != nil {
+				return removed, err
+			}
+			removed += count
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return removed, nil
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package core
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -31,9 +30,8 @@ func (g *Game) checkIsDefender(userId string) string {
 }
 
 func (g *Game) checkUserHasCard(user *User, card Card) string {
-	card, err := getCardBySuitAndRank(user.Cards, card.Suit, card.Rank)
+	_, err := getCardBySuitAndRank(user.Cards, card.Suit, card.Rank)
 	if err != nil {
-		fmt.Println(card)
 		return ERROR_USER_NO_HAS_CARD
 	}
 	return ERROR_EMPTY
@@ -44,7 +42,7 @@ func (g *Game) checkAttackTimer() string {
 		return ERROR_EMPTY
 	}
 	now := time.Now()
-	if now.Sub(g.AttackTimerStartedAt).Seconds() >= g.Settings.TimeOver {
+	if now.Sub(g.AttackTimerStartedAt) >= g.Settings.turnTime() {
 		return ERROR_ATTACK_TIME_OVER
 	}
 	return ERROR_EMPTY
@@ -55,7 +53,7 @@ func (g *Game) checkDefendTimer() string {
 		return ERROR_EMPTY
 	}
 	now := time.Now()
-	if now.Sub(g.DefendTimerStartedAt).Seconds() >= g.Settings.TimeOver {
+	if now.Sub(g.DefendTimerStartedAt) >= g.Settings.turnTime() {
 		return ERROR_DEFEND_TIME_OVER
 	}
 	return ERROR_EMPTY
@@ -1,9 +1,5 @@
 package core
 
-import (
-	"time"
-)
-
 // Attacker end attack
 func (g *Game) EndAttackHandler(command Command, user *User) CommandResponse {
 	gameErrors := []string{
@@ -33,11 +29,11 @@ func (g *Game) EndAttackHandler(command Command, user *User) CommandResponse {
 		g.EndAttack(true)
 
 		if len(g.Deck) == 0 && len(defendUser.Cards) == 0 && len(attackUser.Cards) == 0 {
-			g.AddEventToBuffer(NewEndGameEvent(GameResultDraw))
+			g.EndGame(GameResultDraw)
 		} else if len(g.Deck) == 0 && len(defendUser.Cards) == 0 {
-			g.AddEventToBuffer(NewEndGameEvent(GameResultWin))
+			g.EndGame(GameResultWin)
 		} else if len(g.Deck) == 0 && len(attackUser.Cards) == 0 {
-			g.AddEventToBuffer(NewEndGameEvent(GameResultWin))
+			g.EndGame(GameResultWin)
 		}
 	}
 
@@ -241,11 +237,12 @@ func (g *Game) CheckAttackTimerHandler(command Command, user *User) CommandRespo
 	}
 
 	if g.checkAttackTimer() == ERROR_ATTACK_TIME_OVER {
+		g.expiredTimer = TimerAttack
 		g.EndAttack(true)
 	}
 
 	if g.AttackTimerIsRunning {
-		timeEndAt := g.AttackTimerStartedAt.Add(time.Duration(g.Settings.TimeOver) * time.Second)
+		timeEndAt := g.AttackTimerStartedAt.Add(g.Settings.turnTime())
 		g.AddEventToBuffer(
 			NewAttackTimerStateEvent(false, &timeEndAt),
 		)
@@ -276,11 +273,12 @@ func (g *Game) CheckDefendTimerHandler(command Command, user *User) CommandRespo
 	}
 
 	if g.checkDefendTimer() == ERROR_DEFEND_TIME_OVER {
+		g.expiredTimer = TimerDefend
 		g.EndAttack(true)
 	}
 
 	if g.DefendTimerIsRunning {
-		timeEndAt := g.DefendTimerStartedAt.Add(time.Duration(g.Settings.TimeOver) * time.Second)
+		timeEndAt := g.DefendTimerStartedAt.Add(g.Settings.turnTime())
 		g.AddEventToBuffer(
 			NewDefendTimerStateEvent(false, &timeEndAt),
 		)
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand/v2"
 	"time"
 
@@ -15,12 +15,42 @@ import (
 
 var (
 	DefaultGameSettings = GameSettings{
-		TimeOver: 3000000000,
+		TimeOver: 30 * time.Second,
+		DeckSize: DeckSizeClassic,
 	}
+
+	ErrInvalidDeckSize = errors.New("deck size must be 24, 36 or 52")
+	ErrPlayerCount     = errors.New("the deck cannot deal a hand to every player")
 )
 
+const (
+	DeckSizeShort   = 24
+	DeckSizeClassic = 36
+	DeckSizeFull    = 52
+	handSize        = 6
+)
+
+// handledCommandsLimit bounds how many recent command ids a game remembers
+// for deduplication of redelivered messages.
+const handledCommandsLimit = 100
+
 type GameSettings struct {
-	TimeOver float64
+	// TimeOver is the time a player has for a turn. Games stored before it
+	// was a duration have none and use the default.
+	TimeOver time.Duration `json:"time_over,omitempty"`
+	Mode     string        `json:"mode,omitempty"`
+	DeckSize int           `json:"deck_size,omitempty"`
+	// Ranked only labels the game. Nothing reads it yet: match results and
+	// ratings are handled by the players service, which does not get it.
+	Ranked bool `json:"ranked"`
+}
+
+// turnTime is TimeOver, or the default when it is not set.
+func (s *GameSettings) turnTime() time.Duration {
+	if s == nil || s.TimeOver <= 0 {
+		return DefaultGameSettings.TimeOver
+	}
+	return s.TimeOver
 }
 
 type Card struct {
@@ -70,6 +100,8 @@ type Game struct {
 	EndAttackUserId []string      `json:"end_attack_user_id"`
 	ReadyUsers      []string      `json:"ready_users"`
 	IsStarted       bool          `json:"is_Started"`
+	IsEnded         bool          `json:"is_ended"`
+	Result          GameResult    `json:"result,omitempty"`
 
 	AttackTimerIsRunning bool      `json:"attack_timer_is_running"`
 	AttackTimerStartedAt time.Time `json:"attack_timer_started_at"`
@@ -80,54 +112,81 @@ type Game struct {
 	DefendTimerEndedAt   time.Time `json:"defend_timer_ended_at"`
 
 	GameEventBuffer []GameEventContainer `json:"game_event_buffer"`
+	// EventSeq counts the events the game has produced so far.
+	EventSeq int64 `json:"event_seq"`
+
+	HandledCommandIds []string `json:"handled_command_ids"`
+
+	// expiredTimer is set while handling a command that found a turn timer
+	// run out.
+	expiredTimer string
 }
 
-func CreateNewGameAndSaveInRedis(redis *redis.Client, userIds []string) (*Game, error) { // TODO: move to handler layer
-	game, err := CreateNewGame(userIds)
+func CreateNewGameAndSaveInRedis(ctx context.Context, redis *redis.Client, userIds []string, settings GameSettings) (*Game, error) { // TODO: move to handler layer
+	game, err := CreateNewGameWithSettings(userIds, settings)
 
 	if err != nil {
 		return nil, err
 	}
 
-	result, _ := json.Marshal(game)
-	log.Print(string(result))
+	result, err := json.Marshal(game)
+	if err != nil {
+		return nil, err
+	}
 
-	ctx := context.Background()
-	// TODO: Change expiration time
-	status := redis.Set(ctx, "game:"+game.Id, string(result), 0)
-	if status.Err() != nil {
-		log.Fatal("Couldn't create game room: " + game.Id)
-	} else {
-		log.Print("Create game room: " + game.Id)
+	if err := storeGame(ctx, redis, game, result); err != nil {
+		slog.ErrorContext(ctx, "Couldn't create game room", "game_id", game.Id, "error", err)
+		return nil, err
 	}
+	slog.InfoContext(ctx, "Create game room", "game_id", game.Id, "mode", settings.Mode, "players", len(userIds))
 
 	return game, nil
 }
 
-func SaveGame(game *Game, redis *redis.Client) { // TODO: move to handler layer
-	result, _ := json.Marshal(game)
-	log.Print(string(result))
+func SaveGame(ctx context.Context, game *Game, redis *redis.Client) error { // TODO: move to handler layer
+	result, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
 
-	ctx := context.Background()
-	// TODO: Change expiration time
-	status := redis.Set(ctx, "game:"+game.Id, string(result), 0)
-	if status.Err() != nil {
-		log.Fatal("Couldn't save game room: " + game.Id)
-	} else {
-		log.Print("Save game room: " + game.Id)
+	if err := storeGame(ctx, redis, game, result); err != nil {
+		slog.ErrorContext(ctx, "Couldn't save game room", "game_id", game.Id, "error", err)
+		return err
 	}
+
+	slog.DebugContext(ctx, "Save game room", "game_id", game.Id, "event_seq", game.EventSeq)
+	return nil
 }
 
 func CreateNewGame(userIds []string) (*Game, error) {
+	return CreateNewGameWithSettings(userIds, DefaultGameSettings)
+}
+
+// CreateNewGameWithSettings deals a game of settings.DeckSize cards, the
+// classic deck when it is unset.
+func CreateNewGameWithSettings(userIds []string, settings GameSettings) (*Game, error) {
+	if settings.DeckSize == 0 {
+		settings.DeckSize = DeckSizeClassic
+	}
+	if settings.DeckSize != DeckSizeShort && settings.DeckSize != DeckSizeClassic && settings.DeckSize != DeckSizeFull {
+		return nil, ErrInvalidDeckSize
+	}
+	if len(userIds) < 2 || len(userIds)*handSize > settings.DeckSize {
+		return nil, ErrPlayerCount
+	}
+	if settings.TimeOver == 0 {
+		settings.TimeOver = DefaultGameSettings.TimeOver
+	}
+
 	id := uuid.New()
-	deck := generateDeck()
+	deck := generateDeck(settings.DeckSize)
 	trum_suit := deck[0].Suit
 
 	shackeCards(deck)
 	users := make([]*User, len(userIds))
 	for i := range users {
-		userCards := deck[len(deck)-6:]
-		deck = deck[:len(deck)-6]
+		userCards := deck[len(deck)-handSize:]
+		deck = deck[:len(deck)-handSize]
 
 		users[i] = &User{
 			Id:     userIds[i],
@@ -140,7 +199,7 @@ func CreateNewGame(userIds []string) (*Game, error) {
 
 	game := Game{
 		Id:         id.String(),
-		Settings:   &DefaultGameSettings,
+		Settings:   &settings,
 		Users:      users,
 		Deck:       deck,
 		TrumpSuit:  trum_suit,
@@ -159,39 +218,103 @@ func CreateNewGame(userIds []string) (*Game, error) {
 	return &game, nil
 }
 
-func LoadGame(redis *redis.Client, gameId string) (*Game, error) {
-	ctx := context.Background()
-	value, err := redis.Get(ctx, "game:"+gameId).Result()
+func LoadGame(ctx context.Context, redis *redis.Client, gameId string) (*Game, error) {
+	game, err := ReadGame(ctx, redis, gameId)
 	if err != nil {
 		return nil, err
 	}
 
-	var game Game
+	slog.DebugContext(ctx, "Load game room", "game_id", gameId, "event_seq", game.EventSeq)
 
-	log.Print(value)
-	err = json.Unmarshal([]byte(value), &game)
+	return game, nil
+}
 
+// ReadGame reads a game stored by the game service. Other services use it
+// to look at games without going through the game service.
+func ReadGame(ctx context.Context, redis *redis.Client, gameId string) (*Game, error) {
+	value, err := redis.Get(ctx, "game:"+gameId).Bytes()
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Success load game (%s)\n%s", gameId, value)
+	var game Game
+	if err := json.Unmarshal(value, &game); err != nil {
+		return nil, err
+	}
 
-	return &game, err
+	return &game, nil
+}
+
+// StateFor returns the game state as seen by the user.
+func (g *Game) StateFor(userId string) (GameStateResponse, error) {
+	user, err := g.getUserById(userId)
+	if err != nil {
+		return GameStateResponse{}, err
+	}
+
+	return gameToGameStateResponse(g, user), nil
+}
+
+// Timers returns the turn timers, with the time they run out at while they
+// are running.
+func (g *Game) Timers() TimersResponse {
+	timeOver := g.Settings.turnTime()
+
+	timers := TimersResponse{
+		Attack: TimerResponse{IsRunning: g.AttackTimerIsRunning},
+		Defend: TimerResponse{IsRunning: g.DefendTimerIsRunning},
+	}
+	if g.AttackTimerIsRunning {
+		endAt := g.AttackTimerStartedAt.Add(timeOver)
+		timers.Attack.EndAt = &endAt
+	}
+	if g.DefendTimerIsRunning {
+		endAt := g.DefendTimerStartedAt.Add(timeOver)
+		timers.Defend.EndAt = &endAt
+	}
+	return timers
 }
 
 func (g *Game) HandleMessage(msg []byte) (map[string][]byte, error) {
+	result, err := g.HandleCommand(msg)
+	if err != nil {
+		return nil, err
+	}
+	return result.Packs, nil
+}
+
+// CommandResult describes what a handled command did to the game.
+type CommandResult struct {
+	Action string
+	Error  string
+	// Started and Ended are set when the command started or ended the game.
+	Started bool
+	Ended   bool
+	// ExpiredTimer is TimerAttack or TimerDefend when the command found the
+	// turn timer run out.
+	ExpiredTimer string
+	Packs        map[string][]byte
+}
+
+// HandleCommand is HandleMessage that also reports the outcome of the
+// command.
+func (g *Game) HandleCommand(msg []byte) (CommandResult, error) {
 	var command Command
 	err := json.Unmarshal(msg, &command)
 	if err != nil {
-		return nil, err
+		return CommandResult{}, err
 	}
 
 	user, err := g.getUserById(command.UserId)
 	if err != nil {
-		return nil, err
+		return CommandResult{}, err
 	}
 
+	wasStarted, wasEnded := g.IsStarted, g.IsEnded
+	g.expiredTimer = ""
+
+	g.rememberCommand(command.CommandId)
+
 	var response CommandResponse
 
 	switch command.Action {
@@ -221,7 +344,44 @@ func (g *Game) HandleMessage(msg []byte) (map[string][]byte, error) {
 		}
 	}
 
-	return g.GeneratePack(response, user), nil
+	return CommandResult{
+		Action:       command.Action,
+		Error:        response.Error,
+		Started:      g.IsStarted && !wasStarted,
+		Ended:        g.IsEnded && !wasEnded,
+		ExpiredTimer: g.expiredTimer,
+		Packs:        g.GeneratePack(response, user),
+	}, nil
+}
+
+func (g *Game) HasHandledCommand(commandId string) bool {
+	return commandId != "" && contains(g.HandledCommandIds, commandId)
+}
+
+// DuplicatePack answers a command that was already handled with the current
+// state of the game, for clients retrying after a lost response.
+func (g *Game) DuplicatePack(command Command) ([]byte, error) {
+	user, err := g.getUserById(command.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	state := gameToGameStateResponse(g, user)
+	return json.Marshal(MessagePack{
+		Messages:  []any{CommandResponse{Command: command, State: state}},
+		GameState: state,
+	})
+}
+
+func (g *Game) rememberCommand(commandId string) {
+	if commandId == "" {
+		return
+	}
+
+	g.HandledCommandIds = append(g.HandledCommandIds, commandId)
+	if len(g.HandledCommandIds) > handledCommandsLimit {
+		g.HandledCommandIds = g.HandledCommandIds[len(g.HandledCommandIds)-handledCommandsLimit:]
+	}
 }
 
 func (g *Game) StartAttackTimer() {
@@ -300,11 +460,6 @@ func (g *Game) CreateMessangePackByUserFromEventBuffer() map[string]MessagePack
 
 		eventPackEvents := []any{}
 		for _, event := range events {
-
-			estring, _ := json.Marshal(event)
-			fmt.Println("---------------------" + user.Id)
-			fmt.Println(string(estring))
-			fmt.Println("---------------------")
 			eventPackEvents = append(eventPackEvents, event)
 		}
 		result[user.Id] = MessagePack{
@@ -318,12 +473,13 @@ func (g *Game) CreateMessangePackByUserFromEventBuffer() map[string]MessagePack
 	return result
 }
 
-func generateDeck() []Card {
-	deck := make([]Card, 36)
+// generateDeck returns the size highest cards of every suit, up to the ace.
+func generateDeck(size int) []Card {
+	deck := make([]Card, size)
 	i := 0
 
 	for suit := 1; suit <= 4; suit++ {
-		for rank := 6; rank <= 14; rank++ {
+		for rank := 15 - size/4; rank <= 14; rank++ {
 			deck[i] = Card{
 				Suit: suit,
 				Rank: rank,
@@ -485,6 +641,15 @@ func cardInfo(cards []Card) string {
 
 func (g *Game) AddEventToBuffer(event GameEventContainer) {
 	g.GameEventBuffer = append(g.GameEventBuffer, event)
+	g.EventSeq++
+}
+
+// EndGame marks the game as ended, which drops it from the active games of
+// its players on the next save.
+func (g *Game) EndGame(result GameResult) {
+	g.IsEnded = true
+	g.Result = result
+	g.AddEventToBuffer(NewEndGameEvent(result))
 }
 
 func (g *Game) EndAttack(switchUsers bool) {
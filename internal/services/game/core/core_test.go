@@ -1,10 +1,15 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 func nextPlace(place int, usersLength int) int {
@@ -379,7 +384,7 @@ func TestDefendTimeOver(t *testing.T) {
 		t.Error(err)
 	}
 
-	game.Settings.TimeOver = 0.0001
+	game.Settings.TimeOver = 100 * time.Microsecond
 
 	attackUser.Cards[0] = Card{
 		Suit: 1,
@@ -437,7 +442,7 @@ func TestAttackTimeOver(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	game.Settings.TimeOver = 0.0001
+	game.Settings.TimeOver = 100 * time.Microsecond
 
 	for i := range 2 {
 		attackUser.Cards[i] = Card{
@@ -626,3 +631,156 @@ func Test3UserGame(t *testing.T) {
 		t.Error("Last attacker should be observer")
 	}
 }
+
+func TestHandledCommandIds(t *testing.T) {
+	game, _ := CreateNewGame([]string{"user1", "user2"})
+
+	message, _ := json.Marshal(Command{
+		GameId:    game.Id,
+		Action:    ACTION_READY,
+		UserId:    "user1",
+		CommandId: "command1",
+	})
+
+	if game.HasHandledCommand("command1") {
+		t.Error("Command should not be handled before HandleMessage")
+	}
+
+	_, err := game.HandleMessage(message)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !game.HasHandledCommand("command1") {
+		t.Error("Command should be remembered after HandleMessage")
+	}
+
+	if game.HasHandledCommand("") {
+		t.Error("Commands without id should never be deduplicated")
+	}
+
+	for i := range handledCommandsLimit {
+		game.rememberCommand(fmt.Sprintf("filler%d", i))
+	}
+
+	if game.HasHandledCommand("command1") {
+		t.Error("Oldest command id should be evicted once the limit is reached")
+	}
+}
+
+func TestDuplicatePack(t *testing.T) {
+	game, _ := CreateNewGame([]string{"user1", "user2"})
+
+	command := Command{GameId: game.Id, Action: ACTION_READY, UserId: "user1", CommandId: "command1"}
+	message, _ := json.Marshal(command)
+	if _, err := game.HandleMessage(message); err != nil {
+		t.Fatal(err)
+	}
+
+	pack, err := game.DuplicatePack(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded MessagePack
+	if err := json.Unmarshal(pack, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Messages) != 1 || decoded.GameState.Me.Id != "user1" {
+		t.Errorf("Duplicate should be answered with the current state, got %+v", decoded)
+	}
+}
+
+func TestHandleCommandResult(t *testing.T) {
+	game, _ := CreateNewGame([]string{"user1", "user2"})
+
+	ready := func(userId string) CommandResult {
+		message, _ := json.Marshal(Command{GameId: game.Id, Action: ACTION_READY, UserId: userId})
+		result, err := game.HandleCommand(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := ready("user1"); result.Started || result.Action != ACTION_READY || result.Error != ERROR_EMPTY {
+		t.Errorf("Unexpected result of the first ready: %+v", result)
+	}
+	if result := ready("user1"); result.Error != ERROR_USER_ALREADY_READY {
+		t.Errorf("Expected %s, got %+v", ERROR_USER_ALREADY_READY, result)
+	}
+	if result := ready("user2"); !result.Started || len(result.Packs) != 2 {
+		t.Errorf("The last ready should start the game, got %+v", result)
+	}
+
+	game.Settings = &GameSettings{TimeOver: time.Nanosecond}
+	message, _ := json.Marshal(Command{GameId: game.Id, Action: ACTION_CHECK_ATTACK_TIMER, UserId: "user1"})
+	result, err := game.HandleCommand(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExpiredTimer != TimerAttack {
+		t.Errorf("Expected the attack timer to expire, got %+v", result)
+	}
+}
+
+func TestCreateNewGameWithSettings(t *testing.T) {
+	game, err := CreateNewGameWithSettings([]string{"user1", "user2", "user3"}, GameSettings{Mode: "quick-24card", DeckSize: DeckSizeShort})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(game.Deck) != DeckSizeShort-3*handSize {
+		t.Errorf("Expected %d cards in the deck, got %d", DeckSizeShort-3*handSize, len(game.Deck))
+	}
+	for _, user := range game.Users {
+		for _, card := range user.Cards {
+			if card.Rank < 9 {
+				t.Errorf("Short deck dealt %+v", card)
+			}
+		}
+	}
+	if game.Settings.TimeOver != DefaultGameSettings.TimeOver {
+		t.Errorf("Expected the default turn time, got %v", game.Settings.TimeOver)
+	}
+
+	if _, err := CreateNewGameWithSettings(make([]string, 5), GameSettings{DeckSize: DeckSizeShort}); !errors.Is(err, ErrPlayerCount) {
+		t.Errorf("Expected ErrPlayerCount, got %v", err)
+	}
+	if _, err := CreateNewGameWithSettings([]string{"user1", "user2"}, GameSettings{DeckSize: 30}); !errors.Is(err, ErrInvalidDeckSize) {
+		t.Errorf("Expected ErrInvalidDeckSize, got %v", err)
+	}
+}
+
+func TestSweepActiveGames(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	ctx := context.Background()
+
+	abandoned, err := CreateNewGameAndSaveInRedis(ctx, client, []string{"u1", "u2"}, GameSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(abandonedGameTTL / 2)
+
+	played, err := CreateNewGameAndSaveInRedis(ctx, client, []string{"u3", "u4"}, GameSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(abandonedGameTTL/2 + time.Minute)
+
+	removed, err := SweepActiveGames(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 swept game, got %d", removed)
+	}
+
+	ids, err := client.SMembers(ctx, activeGamesKey).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != played.Id {
+		t.Errorf("Expected only %s to stay active, got %v (abandoned %s)", played.Id, ids, abandoned.Id)
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 const (
 	ACTION_READY              = "ACTION_READY"
 	ACTION_ATTACK             = "ACTION_ATTACK"
@@ -46,6 +48,7 @@ func gameToGameStateResponse(game *Game, targetUser *User) GameStateResponse {
 		DeckLength:  len(game.Deck),
 		TrumpSuit:   game.TrumpSuit,
 		TableCards:  game.TableCards,
+		EventSeq:    game.EventSeq,
 	}
 }
 
@@ -75,13 +78,30 @@ type GameStateResponse struct {
 	DeckLength  int            `json:"deck_length"`
 	TrumpSuit   int            `json:"trump_suit"`
 	TableCards  []TableCard    `json:"table_cards"`
+	EventSeq    int64          `json:"event_seq"`
+}
+
+const (
+	TimerAttack = "attack"
+	TimerDefend = "defend"
+)
+
+type TimerResponse struct {
+	IsRunning bool       `json:"is_running"`
+	EndAt     *time.Time `json:"end_at,omitempty"`
+}
+
+type TimersResponse struct {
+	Attack TimerResponse `json:"attack"`
+	Defend TimerResponse `json:"defend"`
 }
 
 // Requeste messages
 type Command struct {
-	GameId string `json:"game_id"`
-	Action string `json:"action"`
-	UserId string `json:"user_id"`
+	GameId    string `json:"game_id"`
+	Action    string `json:"action"`
+	UserId    string `json:"user_id"`
+	CommandId string `json:"command_id,omitempty"` // client-supplied, used to drop redelivered commands
 }
 
 type AttackCommand struct {
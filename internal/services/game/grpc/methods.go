@@ -2,10 +2,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/game/config"
 	"github.com/MommusWinner/MicroDurak/internal/services/game/controller"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type GameServer struct {
@@ -22,7 +26,16 @@ func (gs *GameServer) CreateGame(
 	ctx context.Context,
 	req *game.CreateGameRequest,
 ) (*game.CreateGameResponse, error) {
-	createdGame, err := gs.GameController.CreateGame(req.UserIds)
+	settings := core.GameSettings{
+		Mode:     req.GetSettings().GetMode(),
+		DeckSize: int(req.GetSettings().GetDeckSize()),
+		Ranked:   req.GetSettings().GetRanked(),
+	}
+
+	createdGame, err := gs.GameController.CreateGame(ctx, req.UserIds, settings)
+	if errors.Is(err, core.ErrInvalidDeckSize) || errors.Is(err, core.ErrPlayerCount) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	Commands = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_commands_total",
+			Help: "Total number of handled commands by action and error code",
+		},
+		[]string{"action", "error"},
+	)
+
+	Duration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "game_operation_duration_seconds",
+			Help:    "Time to load a game, handle a command and save the game",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12),
+		},
+		[]string{"operation"},
+	)
+
+	GamesStarted = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "game_games_started_total",
+			Help: "Total number of started games",
+		},
+	)
+
+	GamesFinished = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_games_finished_total",
+			Help: "Total number of finished games by result",
+		},
+		[]string{"result"},
+	)
+
+	UpdatesFailed = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "game_updates_failed_total",
+			Help: "Total number of game updates that could not be sent to game-manager",
+		},
+	)
+
+	TimersExpired = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "game_timers_expired_total",
+			Help: "Total number of turn timers that ran out",
+		},
+		[]string{"timer"},
+	)
+)
+
+// RegisterActiveGames exposes the number of games that have not ended. Every
+// pod reports the same shared count, so aggregate it with max.
+func RegisterActiveGames(count func() float64) {
+	promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "game_active_games",
+			Help: "Current number of games that have not ended",
+		},
+		count,
+	)
+}
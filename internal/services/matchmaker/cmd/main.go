@@ -9,6 +9,8 @@ import (
 
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/core"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/delivery/http"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 
@@ -22,6 +24,14 @@ func run(ctx context.Context, e *echo.Echo) error {
 	di := core.NewDi()
 	defer core.DisposeCtx(di.Ctx.(*core.Ctx))
 
+	shutdownTracing, err := tracing.Setup(ctx, "matchmaker", di.Ctx.Config().GetTracing())
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+	e.Use(tracing.Middleware("matchmaker"))
+	e.Use(logging.Middleware(di.Ctx.Logger()))
+
 	http.AddRoutes(e, di.Handler)
 
 	errChan := make(chan error, 2)
@@ -2,22 +2,45 @@ package connection
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
 type connection struct {
-	client                *redis.Client
-	matchmakerRepository repositories.MatchmakerRepository
+	client    *redis.Client
+	tickets   domain.Tickets
+	parties   repositories.PartyRepository
+	lobbies   repositories.LobbyRepository
+	penalties repositories.PenaltyRepository
+	searches  repositories.SearchRepository
+	leader    repositories.LeaderRepository
+
+	mu           sync.Mutex
+	repositories map[string]repositories.MatchmakerRepository
+}
+
+// NewConnection wraps an existing redis client, e.g. one pointing at an
+// in-memory redis in tests.
+func NewConnection(client *redis.Client) domain.Connection {
+	return makeConnection(client)
 }
 
 func makeConnection(client *redis.Client) *connection {
 	return &connection{
-		client:                client,
-		matchmakerRepository: NewMatchmakerRepository(client),
+		client:       client,
+		tickets:      ticket.NewStore(client, ticket.DefaultTTL),
+		parties:      NewPartyRepository(client),
+		lobbies:      NewLobbyRepository(client),
+		penalties:    NewPenaltyRepository(client),
+		searches:     NewSearchRepository(client),
+		leader:       NewLeaderRepository(client),
+		repositories: make(map[string]repositories.MatchmakerRepository),
 	}
 }
 
@@ -26,8 +49,11 @@ func Make(cfg infra.Config) domain.Connection {
 	if err != nil {
 		panic(fmt.Sprintf("unable to parse redis URL due [%s]", err))
 	}
-	
+
 	client := redis.NewClient(opt)
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		panic(fmt.Sprintf("unable to instrument redis due [%s]", err))
+	}
 	return makeConnection(client)
 }
 
@@ -36,6 +62,38 @@ func Close(conn domain.Connection) {
 	c.client.Close()
 }
 
-func (c *connection) MatchmakerRepository() repositories.MatchmakerRepository {
-	return c.matchmakerRepository
+func (c *connection) MatchmakerRepository(mode string) repositories.MatchmakerRepository {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	repository, ok := c.repositories[mode]
+	if !ok {
+		repository = NewMatchmakerRepository(c.client, mode)
+		c.repositories[mode] = repository
+	}
+	return repository
+}
+
+func (c *connection) PartyRepository() repositories.PartyRepository {
+	return c.parties
+}
+
+func (c *connection) LobbyRepository() repositories.LobbyRepository {
+	return c.lobbies
+}
+
+func (c *connection) PenaltyRepository() repositories.PenaltyRepository {
+	return c.penalties
+}
+
+func (c *connection) SearchRepository() repositories.SearchRepository {
+	return c.searches
+}
+
+func (c *connection) LeaderRepository() repositories.LeaderRepository {
+	return c.leader
+}
+
+func (c *connection) Tickets() domain.Tickets {
+	return c.tickets
 }
@@ -0,0 +1,31 @@
+package connection
+
+import (
+	"context"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+const leaderKey = "matchmaking:leader"
+
+type leaderRepository struct {
+	client *redis.Client
+}
+
+func NewLeaderRepository(client *redis.Client) repositories.LeaderRepository {
+	return &leaderRepository{client: client}
+}
+
+func (r *leaderRepository) Acquire(ctx context.Context, pod string, ttl time.Duration) (bool, error) {
+	leader, err := acquireLeaderScript.Run(ctx, r.client, []string{leaderKey}, pod, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return leader == 1, nil
+}
+
+func (r *leaderRepository) Release(ctx context.Context, pod string) error {
+	return releaseLeaderScript.Run(ctx, r.client, []string{leaderKey}, pod).Err()
+}
@@ -0,0 +1,213 @@
+package connection
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+const lobbyKeyFmt = "lobby:%s"
+const lobbyEventsKeyFmt = "lobby:%s:events"
+const playerLobbyKeyFmt = "lobby:player:%s"
+const publicLobbiesKey = "lobbies:public"
+
+// lobbyTTL closes lobbies nobody touched for a while, every write
+// refreshes it.
+const lobbyTTL = 30 * time.Minute
+
+// Invite codes avoid characters that are easily mistaken for each other.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+const codeLength = 6
+
+const maxCodeAttempts = 5
+const maxUpdateAttempts = 10
+const maxPublicLobbies = 50
+
+var errNoFreeCode = errors.New("no free lobby code")
+
+type lobbyRepository struct {
+	client *redis.Client
+}
+
+func NewLobbyRepository(client *redis.Client) repositories.LobbyRepository {
+	return &lobbyRepository{client: client}
+}
+
+func newCode() (string, error) {
+	raw := make([]byte, codeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, codeLength)
+	for i, b := range raw {
+		code[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(code), nil
+}
+
+func (r *lobbyRepository) CreateLobby(ctx context.Context, lobby models.Lobby) (models.Lobby, error) {
+	for range maxCodeAttempts {
+		code, err := newCode()
+		if err != nil {
+			return models.Lobby{}, err
+		}
+		lobby.Code = code
+
+		value, err := json.Marshal(lobby)
+		if err != nil {
+			return models.Lobby{}, err
+		}
+
+		created, err := r.client.SetNX(ctx, fmt.Sprintf(lobbyKeyFmt, code), value, lobbyTTL).Result()
+		if err != nil {
+			return models.Lobby{}, err
+		}
+		if !created {
+			continue
+		}
+
+		_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, fmt.Sprintf(playerLobbyKeyFmt, lobby.HostId), code, lobbyTTL)
+			if lobby.Settings.Public {
+				pipe.ZAdd(ctx, publicLobbiesKey, redis.Z{Score: float64(time.Now().Unix()), Member: code})
+			}
+			return nil
+		})
+		return lobby, err
+	}
+	return models.Lobby{}, errNoFreeCode
+}
+
+func (r *lobbyRepository) GetLobby(ctx context.Context, code string) (models.Lobby, error) {
+	return getLobby(ctx, r.client, code)
+}
+
+func getLobby(ctx context.Context, client redis.Cmdable, code string) (models.Lobby, error) {
+	value, err := client.Get(ctx, fmt.Sprintf(lobbyKeyFmt, code)).Bytes()
+	if err != nil {
+		return models.Lobby{}, err
+	}
+
+	var lobby models.Lobby
+	err = json.Unmarshal(value, &lobby)
+	return lobby, err
+}
+
+func (r *lobbyRepository) GetPlayerLobby(ctx context.Context, playerId string) (string, error) {
+	code, err := r.client.Get(ctx, fmt.Sprintf(playerLobbyKeyFmt, playerId)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return code, err
+}
+
+func (r *lobbyRepository) UpdateLobby(ctx context.Context, code string, update func(lobby *models.Lobby) error) (models.Lobby, error) {
+	lobbyKey := fmt.Sprintf(lobbyKeyFmt, code)
+
+	for range maxUpdateAttempts {
+		var updated models.Lobby
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			lobby, err := getLobby(ctx, tx, code)
+			if err != nil {
+				return err
+			}
+
+			before := make([]string, len(lobby.Members))
+			for i, member := range lobby.Members {
+				before[i] = member.PlayerId
+			}
+
+			if err := update(&lobby); err != nil {
+				return err
+			}
+
+			value, err := json.Marshal(lobby)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, lobbyKey, value, lobbyTTL)
+				for _, playerId := range before {
+					if lobby.Member(playerId) < 0 {
+						pipe.Del(ctx, fmt.Sprintf(playerLobbyKeyFmt, playerId))
+					}
+				}
+				for _, member := range lobby.Members {
+					pipe.Set(ctx, fmt.Sprintf(playerLobbyKeyFmt, member.PlayerId), code, lobbyTTL)
+				}
+				return nil
+			})
+			updated = lobby
+			return err
+		}, lobbyKey)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		return updated, err
+	}
+	return models.Lobby{}, redis.TxFailedErr
+}
+
+func (r *lobbyRepository) DeleteLobby(ctx context.Context, code string) error {
+	lobby, err := r.GetLobby(ctx, code)
+	if errors.Is(err, redis.Nil) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, fmt.Sprintf(lobbyKeyFmt, code))
+		pipe.ZRem(ctx, publicLobbiesKey, code)
+		for _, member := range lobby.Members {
+			pipe.Del(ctx, fmt.Sprintf(playerLobbyKeyFmt, member.PlayerId))
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *lobbyRepository) ListPublicLobbies(ctx context.Context) ([]models.Lobby, error) {
+	codes, err := r.client.ZRevRange(ctx, publicLobbiesKey, 0, maxPublicLobbies-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	lobbies := make([]models.Lobby, 0, len(codes))
+	for _, code := range codes {
+		lobby, err := r.GetLobby(ctx, code)
+		if errors.Is(err, redis.Nil) {
+			// expired, the index has no TTL of its own
+			r.client.ZRem(ctx, publicLobbiesKey, code)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		lobbies = append(lobbies, lobby)
+	}
+	return lobbies, nil
+}
+
+func (r *lobbyRepository) Publish(ctx context.Context, code string, event models.LobbyEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, fmt.Sprintf(lobbyEventsKeyFmt, code), value).Err()
+}
+
+func (r *lobbyRepository) Subscribe(ctx context.Context, code string) (<-chan models.LobbyEvent, func() error, error) {
+	return subscribe(ctx, r.client, fmt.Sprintf(lobbyEventsKeyFmt, code), func(event models.LobbyEvent) models.LobbyEvent {
+		return event
+	})
+}
@@ -13,19 +13,30 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-const groupQueueKey = "matchmaking:queue:groups"
-const playerQueueKey = "matchmaking:queue:players"
+// Keys are namespaced by the mode of the repository, and every table size
+// has its own player and group queue. The scripts in scripts.go are passed
+// these keys.
+const keyPrefix = "matchmaking:"
+const groupQueueKeyFmt = "queue:groups:%d"
+const playerQueueKeyFmt = "queue:players:%d"
 
-const groupMemFmt = "group:%d"
+const groupsAmountKey = "groups:amount"
 
-const groupsAmountKey = "matchmaking:groups:amount"
-
-const groupKeyFmt = "matchmaking:group:%d"
+const groupKeyFmt = "group:%d"
 const groupMembersKey = ":members"
+const groupSeatsKey = ":seats"
 
-const playerKeyFmt = "matchmaking:player:%s"
+const playerKeyFmt = "player:%s"
 const playerStatusKey = ":status"
 const playerGroupKey = ":group"
+const playerSizeKey = ":size"
+const playerPartyKey = ":party"
+
+const playerTTL = 24 * time.Hour
+
+// removeAttempts bounds the retries of RemovePlayer when the player moves
+// between reading its keys and running the script.
+const removeAttempts = 3
 
 type parseError struct {
 	name string
@@ -40,11 +51,18 @@ func newParseError(name string) *parseError {
 }
 
 type matchmakerRepository struct {
-	client *redis.Client
+	client    *redis.Client
+	namespace string
 }
 
-func NewMatchmakerRepository(client *redis.Client) repositories.MatchmakerRepository {
-	return &matchmakerRepository{client: client}
+// NewMatchmakerRepository stores the queues of mode under
+// "matchmaking:<mode>:".
+func NewMatchmakerRepository(client *redis.Client, mode string) repositories.MatchmakerRepository {
+	return &matchmakerRepository{client: client, namespace: keyPrefix + mode + ":"}
+}
+
+func (r *matchmakerRepository) key(format string, args ...any) string {
+	return r.namespace + fmt.Sprintf(format, args...)
 }
 
 func (r *matchmakerRepository) ParseGroupId(groupString string) (int, error) {
@@ -61,18 +79,29 @@ func (r *matchmakerRepository) ParseGroupId(groupString string) (int, error) {
 	return id, nil
 }
 
-func (r *matchmakerRepository) GetPlayerScore(ctx context.Context, playerId string) (int, error) {
-	score, err := r.client.ZScore(ctx, playerQueueKey, playerId).Result()
+func (r *matchmakerRepository) groupQueueKey(size int) string {
+	return r.key(groupQueueKeyFmt, size)
+}
 
-	if err != nil {
-		return 0, err
-	}
+func (r *matchmakerRepository) playerQueueKey(size int) string {
+	return r.key(playerQueueKeyFmt, size)
+}
+
+func (r *matchmakerRepository) playerKey(playerId string, suffix string) string {
+	return r.key(playerKeyFmt, playerId) + suffix
+}
+
+func (r *matchmakerRepository) groupKey(groupId int, suffix string) string {
+	return r.key(groupKeyFmt, groupId) + suffix
+}
 
-	return int(score), nil
+// ttlArg is the player TTL as passed to the scripts.
+func ttlArg() string {
+	return strconv.Itoa(int(playerTTL.Seconds()))
 }
 
 func (r *matchmakerRepository) GetPlayer(ctx context.Context, playerId string) (models.RedisPlayer, error) {
-	playerKey := fmt.Sprintf(playerKeyFmt, playerId)
+	playerKey := r.key(playerKeyFmt, playerId)
 	statusString, err := r.client.Get(ctx, playerKey+playerStatusKey).Result()
 
 	var player models.RedisPlayer
@@ -90,6 +119,14 @@ func (r *matchmakerRepository) GetPlayer(ctx context.Context, playerId string) (
 	player.Status = status
 	player.Id = playerId
 
+	if status != models.StatusEmpty {
+		size, err := r.client.Get(ctx, playerKey+playerSizeKey).Int()
+		if err != nil {
+			return models.RedisPlayer{}, err
+		}
+		player.Size = size
+	}
+
 	switch status {
 	case models.StatusSearch:
 		return player, nil
@@ -112,47 +149,60 @@ func (r *matchmakerRepository) GetPlayer(ctx context.Context, playerId string) (
 }
 
 func (r *matchmakerRepository) SetPlayerStatus(ctx context.Context, playerId string, status models.PlayerStatus) error {
-	playerKey := fmt.Sprintf(playerKeyFmt, playerId)
-	err := r.client.Set(ctx, playerKey+playerStatusKey, status, 24*time.Hour).Err()
-
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (r *matchmakerRepository) SetPlayerGroup(ctx context.Context, playerId string, group int) error {
-	playerKey := fmt.Sprintf(playerKeyFmt, playerId)
-	err := r.client.Set(ctx, playerKey+playerGroupKey, group, 24*time.Hour).Err()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	keys := []string{r.playerKey(playerId, playerStatusKey), r.playerKey(playerId, playerGroupKey)}
+	return setPlayerStatusScript.Run(ctx, r.client, keys, int(status), ttlArg()).Err()
 }
 
-func (r *matchmakerRepository) AddPlayer(ctx context.Context, playerId string, score int) (models.RedisPlayer, error) {
+func (r *matchmakerRepository) AddPlayer(ctx context.Context, playerId string, score int, size int, party []string) (models.RedisPlayer, error) {
 	player := models.RedisPlayer{Status: models.StatusEmpty, Id: playerId, Gid: 0}
 
-	err := r.client.ZAdd(ctx, playerQueueKey, redis.Z{Score: float64(score), Member: playerId}).Err()
-	if err != nil {
-		return player, err
+	keys := []string{
+		searchesKey,
+		r.playerQueueKey(size),
+		r.playerKey(playerId, playerStatusKey),
+		r.playerKey(playerId, playerSizeKey),
+		r.playerKey(playerId, playerGroupKey),
+		r.playerKey(playerId, playerPartyKey),
+	}
+	args := make([]any, 0, len(party)+4)
+	args = append(args, ttlArg(), playerId, score, size)
+	for _, member := range party {
+		args = append(args, member)
 	}
 
-	err = r.SetPlayerStatus(ctx, playerId, models.StatusSearch)
+	added, err := addPlayerScript.Run(ctx, r.client, keys, args...).Int()
 	if err != nil {
 		return player, err
 	}
+	if added == 0 {
+		return player, repositories.ErrConflict
+	}
 
 	player.Status = models.StatusSearch
+	player.Size = size
 	return player, nil
 }
 
-func (r *matchmakerRepository) ListPlayersRange(ctx context.Context, low int, high int) ([]redis.Z, error) {
+func (r *matchmakerRepository) GetSeats(ctx context.Context, playerId string) (int, error) {
+	playerKey := r.key(playerKeyFmt, playerId)
+	members, err := r.client.SCard(ctx, playerKey+playerPartyKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(members) + 1, nil
+}
+
+func (r *matchmakerRepository) GetPartyMembers(ctx context.Context, playerId string) ([]string, error) {
+	playerKey := r.key(playerKeyFmt, playerId)
+	return r.client.SMembers(ctx, playerKey+playerPartyKey).Result()
+}
+
+func (r *matchmakerRepository) ListPlayersRange(ctx context.Context, size int, low int, high int) ([]redis.Z, error) {
 	lows := fmt.Sprint(low)
 	highs := fmt.Sprint(high)
 
-	player, err := r.client.ZRangeByScoreWithScores(ctx, playerQueueKey, &redis.ZRangeBy{Min: lows, Max: highs}).Result()
+	player, err := r.client.ZRangeByScoreWithScores(ctx, r.playerQueueKey(size), &redis.ZRangeBy{Min: lows, Max: highs}).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -160,90 +210,85 @@ func (r *matchmakerRepository) ListPlayersRange(ctx context.Context, low int, hi
 	return player, nil
 }
 
+// RemovePlayer reads the table size and group of the player to build the
+// keys of its queue, and retries when the script finds they changed.
 func (r *matchmakerRepository) RemovePlayer(ctx context.Context, playerId string) error {
-	player, err := r.GetPlayer(ctx, playerId)
-	if errors.Is(err, redis.Nil) {
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	switch player.Status {
-	case models.StatusEmpty:
-		return nil
-	case models.StatusSearch:
-		err := r.SetPlayerStatus(ctx, playerId, models.StatusEmpty)
+	for range removeAttempts {
+		values, err := r.client.MGet(ctx, r.playerKey(playerId, playerSizeKey), r.playerKey(playerId, playerGroupKey)).Result()
 		if err != nil {
 			return err
 		}
 
-		err = r.client.ZRem(ctx, playerQueueKey, playerId).Err()
-		if err != nil {
-			return err
+		size, gid := 0, 0
+		if value, ok := values[0].(string); ok {
+			size, _ = strconv.Atoi(value)
 		}
-		return nil
-	case models.StatusMoved:
-		err := r.RemoveFromGroup(ctx, player.Gid, playerId)
+		if value, ok := values[1].(string); ok {
+			gid, _ = strconv.Atoi(value)
+		}
+
+		keys := []string{
+			r.playerKey(playerId, playerStatusKey),
+			r.playerKey(playerId, playerSizeKey),
+			r.playerKey(playerId, playerGroupKey),
+			r.playerKey(playerId, playerPartyKey),
+			r.playerQueueKey(size),
+			r.groupQueueKey(size),
+			r.groupKey(gid, groupMembersKey),
+			r.groupKey(gid, groupSeatsKey),
+		}
+		result, err := removePlayerScript.Run(ctx, r.client, keys, ttlArg(), playerId, size, gid).Int()
 		if err != nil {
 			return err
 		}
-		r.SetPlayerStatus(ctx, playerId, models.StatusEmpty)
-		return nil
+		if result != removeStale {
+			return nil
+		}
 	}
 
-	return nil
+	return repositories.ErrConflict
 }
 
-func (r *matchmakerRepository) AddGroup(ctx context.Context, players []redis.Z) error {
-	members := make([]any, 0, len(players))
-	for _, player := range players {
-		members = append(members, player.Member)
-	}
-
-	err := r.client.ZRem(ctx, playerQueueKey, members...).Err()
+// AddGroup takes the id of the group before running the script, an id left
+// unused by a conflict is skipped.
+func (r *matchmakerRepository) AddGroup(ctx context.Context, size int, players []redis.Z) error {
+	gid, err := r.client.Incr(ctx, r.key(groupsAmountKey)).Result()
 	if err != nil {
 		return err
 	}
 
-	scoreSum := 0
+	keys := make([]string, 0, len(players)*3+4)
+	keys = append(keys,
+		r.playerQueueKey(size),
+		r.groupQueueKey(size),
+		r.groupKey(int(gid), groupMembersKey),
+		r.groupKey(int(gid), groupSeatsKey),
+	)
+	args := make([]any, 0, len(players)+2)
+	args = append(args, ttlArg(), gid)
 	for _, player := range players {
-		scoreSum += int(player.Score)
+		playerId := player.Member.(string)
+		keys = append(keys,
+			r.playerKey(playerId, playerStatusKey),
+			r.playerKey(playerId, playerGroupKey),
+			r.playerKey(playerId, playerPartyKey),
+		)
+		args = append(args, playerId)
 	}
 
-	scoreAvg := scoreSum / len(players)
-
-	count, err := r.client.Incr(ctx, groupsAmountKey).Result()
+	added, err := addGroupScript.Run(ctx, r.client, keys, args...).Int()
 	if err != nil {
 		return err
 	}
-
-	playerIds := make([]string, len(players))
-	for i, player := range players {
-		id := player.Member.(string)
-
-		playerIds[i] = id
-
-		r.SetPlayerStatus(ctx, id, models.StatusMoved)
-		r.SetPlayerGroup(ctx, id, int(count))
-	}
-
-	groupKey := fmt.Sprintf(groupKeyFmt, count)
-	err = r.client.SAdd(ctx, groupKey+groupMembersKey, playerIds).Err()
-	if err != nil {
-		return err
-	}
-
-	queueGroupKey := fmt.Sprintf(groupMemFmt, count)
-	err = r.client.ZAdd(ctx, groupQueueKey, redis.Z{Score: float64(scoreAvg), Member: queueGroupKey}).Err()
-	if err != nil {
-		return err
+	if added == 0 {
+		return repositories.ErrConflict
 	}
 
 	return nil
 }
 
-func (r *matchmakerRepository) CountGroups(ctx context.Context) (int, error) {
-	count, err := r.client.ZCard(ctx, groupQueueKey).Result()
+func (r *matchmakerRepository) CountGroups(ctx context.Context, size int) (int, error) {
+	count, err := r.client.ZCard(ctx, r.groupQueueKey(size)).Result()
 	if err != nil {
 		return 0, err
 	}
@@ -251,10 +296,10 @@ func (r *matchmakerRepository) CountGroups(ctx context.Context) (int, error) {
 	return int(count), nil
 }
 
-func (r *matchmakerRepository) ListGroupsRange(ctx context.Context, low int, high int) ([]redis.Z, error) {
+func (r *matchmakerRepository) ListGroupsRange(ctx context.Context, size int, low int, high int) ([]redis.Z, error) {
 	lows := fmt.Sprint(low)
 	highs := fmt.Sprint(high)
-	player, err := r.client.ZRangeByScoreWithScores(ctx, groupQueueKey, &redis.ZRangeBy{Min: lows, Max: highs}).Result()
+	player, err := r.client.ZRangeByScoreWithScores(ctx, r.groupQueueKey(size), &redis.ZRangeBy{Min: lows, Max: highs}).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -262,53 +307,38 @@ func (r *matchmakerRepository) ListGroupsRange(ctx context.Context, low int, hig
 	return player, err
 }
 
-func (r *matchmakerRepository) AddToGroup(ctx context.Context, groupId int, player redis.Z) error {
+func (r *matchmakerRepository) AddToGroup(ctx context.Context, size int, groupId int, player redis.Z) error {
 	playerId := player.Member.(string)
-
-	groupKey := fmt.Sprintf(groupKeyFmt, groupId)
-	groupQueueKey := fmt.Sprintf(groupMemFmt, groupId)
-
-	r.SetPlayerStatus(ctx, playerId, models.StatusMoved)
-	r.SetPlayerGroup(ctx, playerId, groupId)
-
-	len, err := r.GetGroupLen(ctx, groupId)
-	if err != nil {
-		return err
-	}
-
-	oldScore, err := r.GetGroupScore(ctx, groupId)
-	if err != nil {
-		return err
-	}
-
-	newScore := (oldScore*len+int(player.Score))/len + 1
-
-	err = r.client.ZIncrBy(ctx, groupQueueKey, float64(newScore+oldScore), groupQueueKey).Err()
+	keys := []string{
+		r.playerQueueKey(size),
+		r.groupQueueKey(size),
+		r.groupKey(groupId, groupMembersKey),
+		r.groupKey(groupId, groupSeatsKey),
+		r.playerKey(playerId, playerStatusKey),
+		r.playerKey(playerId, playerGroupKey),
+		r.playerKey(playerId, playerPartyKey),
+	}
+	added, err := addToGroupScript.Run(ctx, r.client, keys, ttlArg(), groupId, playerId, size).Int()
 	if err != nil {
 		return err
 	}
-
-	err = r.client.SAdd(ctx, groupKey+groupMembersKey, playerId).Err()
-	if err != nil {
-		panic(err)
+	if added == 0 {
+		return repositories.ErrConflict
 	}
 
 	return nil
 }
 
 func (r *matchmakerRepository) GetGroupLen(ctx context.Context, groupId int) (int, error) {
-	groupKey := fmt.Sprintf(groupKeyFmt, groupId)
-
-	len, err := r.client.SCard(ctx, groupKey+groupMembersKey).Result()
-	if err != nil {
-		return 0, err
+	seats, err := r.client.Get(ctx, r.groupKey(groupId, groupSeatsKey)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
 	}
-
-	return int(len), err
+	return seats, err
 }
 
 func (r *matchmakerRepository) GetGrouppedPlayers(ctx context.Context, groupId int, amount int) ([]string, error) {
-	groupKey := fmt.Sprintf(groupKeyFmt, groupId)
+	groupKey := r.key(groupKeyFmt, groupId)
 	players, err := r.client.SMembers(ctx, groupKey+groupMembersKey).Result()
 	if err != nil {
 		return nil, err
@@ -317,35 +347,11 @@ func (r *matchmakerRepository) GetGrouppedPlayers(ctx context.Context, groupId i
 	return players, err
 }
 
-func (r *matchmakerRepository) RemoveFromGroup(ctx context.Context, groupId int, playerId string) error {
-	err := r.client.SRem(ctx, groupQueueKey, playerId).Err()
+func (r *matchmakerRepository) RemoveGroup(ctx context.Context, size int, groupId int) (bool, error) {
+	keys := []string{r.groupQueueKey(size), r.groupKey(groupId, groupMembersKey), r.groupKey(groupId, groupSeatsKey)}
+	removed, err := removeGroupScript.Run(ctx, r.client, keys, groupId).Int()
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
-}
-
-func (r *matchmakerRepository) RemoveGroup(ctx context.Context, groupId int) error {
-	groupMemKey := fmt.Sprintf(groupMemFmt, groupId)
-	err := r.client.ZRem(ctx, groupQueueKey, groupMemKey).Err()
-	if err != nil {
-		return err
-	}
-
-	groupKey := fmt.Sprintf(groupKeyFmt, groupId)
-	err = r.client.Del(ctx, groupKey+groupMembersKey).Err()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (r *matchmakerRepository) GetGroupScore(ctx context.Context, groupId int) (int, error) {
-	groupKey := fmt.Sprintf(groupMemFmt, groupId)
-	score, err := r.client.ZScore(ctx, groupKey, groupKey).Result()
-	if err != nil {
-		return 0, err
-	}
-
-	return int(score), err
+	return removed == 1, nil
 }
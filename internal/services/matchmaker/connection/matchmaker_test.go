@@ -0,0 +1,198 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRepository(t *testing.T) *matchmakerRepository {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewMatchmakerRepository(client, "test").(*matchmakerRepository)
+}
+
+// addPlayer saves a search for the player and queues it.
+func addPlayer(t *testing.T, r *matchmakerRepository, playerId string, score int, size int, party []string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := r.client.HSet(ctx, searchesKey, playerId, "{}").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddPlayer(ctx, playerId, score, size, party); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addPlayers(t *testing.T, r *matchmakerRepository, size int, scores map[string]int) {
+	t.Helper()
+	for playerId, score := range scores {
+		addPlayer(t, r, playerId, score, size, nil)
+	}
+}
+
+func TestAddGroupTakesPlayersOnce(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+	addPlayers(t, r, 3, map[string]int{"a": 1000, "b": 1200, "c": 1100})
+
+	err := r.AddGroup(ctx, 3, []redis.Z{{Score: 1000, Member: "a"}, {Score: 1200, Member: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// b already is in a group, so c must not be grouped either
+	err = r.AddGroup(ctx, 3, []redis.Z{{Score: 1200, Member: "b"}, {Score: 1100, Member: "c"}})
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+
+	c, err := r.GetPlayer(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != models.StatusSearch {
+		t.Errorf("c should still be searching, got status %d", c.Status)
+	}
+
+	groups, err := r.ListGroupsRange(ctx, 3, 0, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Score != 1100 {
+		t.Errorf("Expected one group rated 1100, got %v", groups)
+	}
+}
+
+func TestAddToGroupRespectsSeatsAndScore(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+	addPlayers(t, r, 3, map[string]int{"a": 1000, "b": 1200})
+	addPlayer(t, r, "leader", 1600, 3, []string{"member"})
+	addPlayers(t, r, 3, map[string]int{"c": 1300})
+
+	if err := r.AddGroup(ctx, 3, []redis.Z{{Score: 1000, Member: "a"}, {Score: 1200, Member: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	player, err := r.GetPlayer(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the party needs two seats, only one is free
+	err = r.AddToGroup(ctx, 3, player.Gid, redis.Z{Score: 1600, Member: "leader"})
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("Expected a conflict for the party, got %v", err)
+	}
+
+	if err := r.AddToGroup(ctx, 3, player.Gid, redis.Z{Score: 1300, Member: "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := r.ListGroupsRange(ctx, 3, 0, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Score != 1166 {
+		t.Errorf("Expected the group rated 1166, got %v", groups)
+	}
+
+	players, err := r.ListPlayersRange(ctx, 3, 0, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 1 || players[0].Member != "leader" {
+		t.Errorf("Only the party should still be queued, got %v", players)
+	}
+}
+
+func TestRemovePlayerLeavesNoEmptyGroup(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+	addPlayers(t, r, 2, map[string]int{"a": 1000, "b": 1000})
+
+	if err := r.AddGroup(ctx, 2, []redis.Z{{Score: 1000, Member: "a"}, {Score: 1000, Member: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, playerId := range []string{"a", "b"} {
+		if err := r.RemovePlayer(ctx, playerId); err != nil {
+			t.Fatal(err)
+		}
+		player, err := r.GetPlayer(ctx, playerId)
+		if err == nil || player.Status != models.StatusEmpty {
+			t.Errorf("%s should have an empty status, got %d", playerId, player.Status)
+		}
+	}
+
+	count, err := r.CountGroups(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no queued groups, got %d", count)
+	}
+
+	// removing twice is fine
+	if err := r.RemovePlayer(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveGroupClaimsOnce(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+	addPlayers(t, r, 2, map[string]int{"a": 1000, "b": 1000})
+
+	if err := r.AddGroup(ctx, 2, []redis.Z{{Score: 1000, Member: "a"}, {Score: 1000, Member: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	player, err := r.GetPlayer(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := r.RemoveGroup(ctx, 2, player.Gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Error("The queued group was not claimed")
+	}
+
+	claimed, err = r.RemoveGroup(ctx, 2, player.Gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed {
+		t.Error("The group was claimed twice")
+	}
+}
+
+func TestAddPlayerNeedsSearch(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+
+	// the search was cancelled before the leader queued the player
+	_, err := r.AddPlayer(ctx, "gone", 1000, 2, nil)
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+
+	players, err := r.ListPlayersRange(ctx, 2, 0, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 0 {
+		t.Errorf("Expected no queued players, got %v", players)
+	}
+}
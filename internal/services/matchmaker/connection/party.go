@@ -0,0 +1,130 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const partyKeyFmt = "party:%s"
+const partyLeaderKey = ":leader"
+const partyMembersKey = ":members"
+const partyInvitesKey = ":invites"
+
+const playerPartyKeyFmt = "party:player:%s"
+
+// partyTTL drops parties that were left alone, every write refreshes it.
+const partyTTL = 24 * time.Hour
+
+type partyRepository struct {
+	client *redis.Client
+}
+
+func NewPartyRepository(client *redis.Client) repositories.PartyRepository {
+	return &partyRepository{client: client}
+}
+
+func (r *partyRepository) CreateParty(ctx context.Context, leaderId string) (models.Party, error) {
+	party := models.Party{Id: uuid.NewString(), LeaderId: leaderId, Members: []string{leaderId}}
+	partyKey := fmt.Sprintf(partyKeyFmt, party.Id)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, partyKey+partyLeaderKey, leaderId, partyTTL)
+		pipe.RPush(ctx, partyKey+partyMembersKey, leaderId)
+		pipe.Expire(ctx, partyKey+partyMembersKey, partyTTL)
+		pipe.Set(ctx, fmt.Sprintf(playerPartyKeyFmt, leaderId), party.Id, partyTTL)
+		return nil
+	})
+	if err != nil {
+		return models.Party{}, err
+	}
+
+	return party, nil
+}
+
+func (r *partyRepository) GetParty(ctx context.Context, partyId string) (models.Party, error) {
+	partyKey := fmt.Sprintf(partyKeyFmt, partyId)
+
+	leaderId, err := r.client.Get(ctx, partyKey+partyLeaderKey).Result()
+	if err != nil {
+		return models.Party{}, err
+	}
+
+	members, err := r.client.LRange(ctx, partyKey+partyMembersKey, 0, -1).Result()
+	if err != nil {
+		return models.Party{}, err
+	}
+
+	// the leader goes first, whenever it joined
+	ordered := make([]string, 0, len(members))
+	ordered = append(ordered, leaderId)
+	for _, member := range members {
+		if member != leaderId {
+			ordered = append(ordered, member)
+		}
+	}
+
+	return models.Party{Id: partyId, LeaderId: leaderId, Members: ordered}, nil
+}
+
+func (r *partyRepository) GetPlayerParty(ctx context.Context, playerId string) (string, error) {
+	partyId, err := r.client.Get(ctx, fmt.Sprintf(playerPartyKeyFmt, playerId)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return partyId, err
+}
+
+func (r *partyRepository) Invite(ctx context.Context, partyId string, playerId string) error {
+	invitesKey := fmt.Sprintf(partyKeyFmt, partyId) + partyInvitesKey
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, invitesKey, playerId)
+		pipe.Expire(ctx, invitesKey, partyTTL)
+		return nil
+	})
+	return err
+}
+
+func (r *partyRepository) AcceptInvite(ctx context.Context, partyId string, playerId string, maxSize int) (models.Party, error) {
+	partyKey := fmt.Sprintf(partyKeyFmt, partyId)
+	keys := []string{
+		fmt.Sprintf(playerPartyKeyFmt, playerId),
+		partyKey + partyInvitesKey,
+		partyKey + partyMembersKey,
+		partyKey + partyLeaderKey,
+	}
+
+	result, err := acceptInviteScript.Run(ctx, r.client, keys, partyId, playerId, maxSize, int(partyTTL.Seconds())).Int()
+	if err != nil {
+		return models.Party{}, err
+	}
+	switch result {
+	case acceptInParty:
+		return models.Party{}, repositories.ErrInParty
+	case acceptNotInvited:
+		return models.Party{}, repositories.ErrNotInvited
+	case acceptPartyFull:
+		return models.Party{}, repositories.ErrPartyFull
+	}
+
+	return r.GetParty(ctx, partyId)
+}
+
+func (r *partyRepository) LeaveParty(ctx context.Context, partyId string, playerId string) (string, error) {
+	partyKey := fmt.Sprintf(partyKeyFmt, partyId)
+	keys := []string{
+		fmt.Sprintf(playerPartyKeyFmt, playerId),
+		partyKey + partyLeaderKey,
+		partyKey + partyMembersKey,
+		partyKey + partyInvitesKey,
+	}
+
+	return leavePartyScript.Run(ctx, r.client, keys, partyId, playerId, int(partyTTL.Seconds())).Text()
+}
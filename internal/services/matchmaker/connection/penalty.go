@@ -0,0 +1,33 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+const penaltyKeyFmt = "matchmaking:penalty:%s"
+
+type penaltyRepository struct {
+	client *redis.Client
+}
+
+func NewPenaltyRepository(client *redis.Client) repositories.PenaltyRepository {
+	return &penaltyRepository{client: client}
+}
+
+func (r *penaltyRepository) Penalize(ctx context.Context, playerId string, duration time.Duration) error {
+	return r.client.Set(ctx, fmt.Sprintf(penaltyKeyFmt, playerId), 1, duration).Err()
+}
+
+func (r *penaltyRepository) PenaltyLeft(ctx context.Context, playerId string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, fmt.Sprintf(penaltyKeyFmt, playerId)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// negative for missing keys
+	return max(ttl, 0), nil
+}
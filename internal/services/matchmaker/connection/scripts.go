@@ -0,0 +1,265 @@
+package connection
+
+import "github.com/redis/go-redis/v9"
+
+// The scripts below move players between the queues atomically, so a player
+// is never in two groups or marked as moved without a group. Every key a
+// script touches is passed in KEYS, built by the repository with the formats
+// in matchmaker.go. Statuses are those of models: 0 empty, 1 search, 2 moved.
+
+// addPlayerScript queues a player for tables of ARGV[4] players if the
+// player still has a search, so a search cancelled while the leader was
+// matching is not queued again. Returns 1, or 0 when nothing changed.
+//
+// KEYS[1] searches, KEYS[2] player queue, KEYS[3] player status,
+// KEYS[4] player size, KEYS[5] player group, KEYS[6] player party
+// ARGV[1] ttl in seconds, ARGV[2] player id, ARGV[3] rating,
+// ARGV[4] table size, ARGV[5..] party members
+var addPlayerScript = redis.NewScript(`
+local ttl, id = ARGV[1], ARGV[2]
+
+if redis.call('HEXISTS', KEYS[1], id) == 0 then
+	return 0
+end
+
+redis.call('SET', KEYS[4], ARGV[4], 'EX', ttl)
+redis.call('DEL', KEYS[5], KEYS[6])
+if #ARGV >= 5 then
+	redis.call('SADD', KEYS[6], unpack(ARGV, 5))
+	redis.call('EXPIRE', KEYS[6], ttl)
+end
+redis.call('ZADD', KEYS[2], ARGV[3], id)
+redis.call('SET', KEYS[3], '1', 'EX', ttl)
+return 1
+`)
+
+// addGroupScript forms the group ARGV[2] of the players in ARGV[3..] if
+// every one of them is still searching. The group is ranked by the average
+// rating of its seats. Returns 1, or 0 when nothing changed.
+//
+// KEYS[1] player queue, KEYS[2] group queue, KEYS[3] group members,
+// KEYS[4] group seats, then for every player its status, group and party
+// keys starting at KEYS[5]
+// ARGV[1] ttl in seconds, ARGV[2] group id, ARGV[3..] player ids
+var addGroupScript = redis.NewScript(`
+local ttl, gid = ARGV[1], ARGV[2]
+
+local sum, seats = 0, 0
+for i = 3, #ARGV do
+	local keys = 5 + (i - 3) * 3
+	local score = redis.call('ZSCORE', KEYS[1], ARGV[i])
+	if not score or redis.call('GET', KEYS[keys]) ~= '1' then
+		return 0
+	end
+	local playerSeats = redis.call('SCARD', KEYS[keys + 2]) + 1
+	sum = sum + tonumber(score) * playerSeats
+	seats = seats + playerSeats
+end
+
+for i = 3, #ARGV do
+	local keys = 5 + (i - 3) * 3
+	redis.call('ZREM', KEYS[1], ARGV[i])
+	redis.call('SET', KEYS[keys], '2', 'EX', ttl)
+	redis.call('SET', KEYS[keys + 1], gid, 'EX', ttl)
+	redis.call('SADD', KEYS[3], ARGV[i])
+end
+redis.call('SET', KEYS[4], seats)
+redis.call('ZADD', KEYS[2], math.floor(sum / seats), 'group:' .. gid)
+return 1
+`)
+
+// addToGroupScript moves a searching player into a queued group that has
+// enough free seats for its party. Returns 1, or 0 when nothing changed.
+//
+// KEYS[1] player queue, KEYS[2] group queue, KEYS[3] group members,
+// KEYS[4] group seats, KEYS[5] player status, KEYS[6] player group,
+// KEYS[7] player party
+// ARGV[1] ttl in seconds, ARGV[2] group id, ARGV[3] player id,
+// ARGV[4] table size
+var addToGroupScript = redis.NewScript(`
+local ttl, gid, id, size = ARGV[1], ARGV[2], ARGV[3], tonumber(ARGV[4])
+
+local score = redis.call('ZSCORE', KEYS[1], id)
+local groupScore = redis.call('ZSCORE', KEYS[2], 'group:' .. gid)
+local groupSeats = tonumber(redis.call('GET', KEYS[4]))
+if not score or not groupScore or not groupSeats or redis.call('GET', KEYS[5]) ~= '1' then
+	return 0
+end
+
+local seats = redis.call('SCARD', KEYS[7]) + 1
+if groupSeats + seats > size then
+	return 0
+end
+
+local newScore = math.floor((tonumber(groupScore) * groupSeats + tonumber(score) * seats) / (groupSeats + seats))
+redis.call('ZADD', KEYS[2], newScore, 'group:' .. gid)
+redis.call('ZREM', KEYS[1], id)
+redis.call('SET', KEYS[5], '2', 'EX', ttl)
+redis.call('SET', KEYS[6], gid, 'EX', ttl)
+redis.call('SADD', KEYS[3], id)
+redis.call('INCRBY', KEYS[4], seats)
+return 1
+`)
+
+// Results of removePlayerScript.
+const (
+	removeNotQueued = iota
+	removeRemoved
+	// removeStale means the table size or group of the player changed since
+	// the keys were built.
+	removeStale
+)
+
+// removePlayerScript takes a player out of its queue or group. A group left
+// without members leaves the group queue. Returns one of the remove
+// results.
+//
+// KEYS[1] player status, KEYS[2] player size, KEYS[3] player group,
+// KEYS[4] player party, KEYS[5] player queue, KEYS[6] group queue,
+// KEYS[7] group members, KEYS[8] group seats
+// ARGV[1] ttl in seconds, ARGV[2] player id, ARGV[3] table size,
+// ARGV[4] group id
+var removePlayerScript = redis.NewScript(`
+local ttl, id = ARGV[1], ARGV[2]
+
+local status = redis.call('GET', KEYS[1])
+if status ~= '1' and status ~= '2' then
+	return 0
+end
+if redis.call('GET', KEYS[2]) ~= ARGV[3] then
+	return 2
+end
+
+if status == '1' then
+	redis.call('ZREM', KEYS[5], id)
+else
+	local gid = redis.call('GET', KEYS[3])
+	if gid and gid ~= ARGV[4] then
+		return 2
+	end
+	if gid and redis.call('SREM', KEYS[7], id) == 1 then
+		redis.call('DECRBY', KEYS[8], redis.call('SCARD', KEYS[4]) + 1)
+		if redis.call('SCARD', KEYS[7]) == 0 then
+			redis.call('ZREM', KEYS[6], 'group:' .. gid)
+			redis.call('DEL', KEYS[8])
+		end
+	end
+end
+
+redis.call('SET', KEYS[1], '0', 'EX', ttl)
+redis.call('DEL', KEYS[3], KEYS[4])
+return 1
+`)
+
+// removeGroupScript takes a group out of its queue and deletes it. Returns
+// 1 when the group was still queued, 0 when another replica claimed it.
+//
+// KEYS[1] group queue, KEYS[2] group members, KEYS[3] group seats
+// ARGV[1] group id
+var removeGroupScript = redis.NewScript(`
+local removed = redis.call('ZREM', KEYS[1], 'group:' .. ARGV[1])
+redis.call('DEL', KEYS[2], KEYS[3])
+return removed
+`)
+
+// setPlayerStatusScript sets the status of a player. A player that is not
+// moved has no group.
+//
+// KEYS[1] player status, KEYS[2] player group
+// ARGV[1] status, ARGV[2] ttl in seconds
+var setPlayerStatusScript = redis.NewScript(`
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+if ARGV[1] ~= '2' then
+	redis.call('DEL', KEYS[2])
+end
+return 1
+`)
+
+// Results of acceptInviteScript.
+const (
+	acceptJoined = iota
+	acceptInParty
+	acceptNotInvited
+	acceptPartyFull
+)
+
+// acceptInviteScript moves an invited player into the party if the player
+// is in no party and the party has a free seat. Returns one of the accept
+// results.
+//
+// KEYS[1] party of the player, KEYS[2] invites, KEYS[3] members,
+// KEYS[4] leader
+// ARGV[1] party id, ARGV[2] player id, ARGV[3] max size, ARGV[4] ttl in
+// seconds
+var acceptInviteScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 1
+end
+if redis.call('EXISTS', KEYS[4]) == 0 or redis.call('SISMEMBER', KEYS[2], ARGV[2]) == 0 then
+	return 2
+end
+if redis.call('LLEN', KEYS[3]) >= tonumber(ARGV[3]) then
+	return 3
+end
+
+redis.call('SREM', KEYS[2], ARGV[2])
+redis.call('RPUSH', KEYS[3], ARGV[2])
+redis.call('EXPIRE', KEYS[3], ARGV[4])
+redis.call('EXPIRE', KEYS[4], ARGV[4])
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[4])
+return 0
+`)
+
+// leavePartyScript takes the player out of the party if it is still a
+// member. The member who joined first after a leaving leader takes over, and
+// the party of the last member is deleted. Returns the leader the party had
+// before, or nil when the player is not in the party.
+//
+// KEYS[1] party of the player, KEYS[2] leader, KEYS[3] members,
+// KEYS[4] invites
+// ARGV[1] party id, ARGV[2] player id, ARGV[3] ttl in seconds
+var leavePartyScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return false
+end
+local leader = redis.call('GET', KEYS[2]) or ''
+
+redis.call('LREM', KEYS[3], 0, ARGV[2])
+redis.call('DEL', KEYS[1])
+local next = redis.call('LINDEX', KEYS[3], 0)
+if not next then
+	redis.call('DEL', KEYS[2], KEYS[3], KEYS[4])
+elseif leader == '' or leader == ARGV[2] then
+	redis.call('SET', KEYS[2], next, 'EX', ARGV[3])
+end
+return leader
+`)
+
+// acquireLeaderScript takes the leader key when it is free and extends it
+// when the replica already holds it. Returns 1 for the leader, 0 otherwise.
+//
+// KEYS[1] leader key
+// ARGV[1] replica, ARGV[2] ttl in milliseconds
+var acquireLeaderScript = redis.NewScript(`
+local leader = redis.call('GET', KEYS[1])
+if not leader then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if leader == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaderScript deletes the leader key if the replica holds it.
+//
+// KEYS[1] leader key
+// ARGV[1] replica
+var releaseLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
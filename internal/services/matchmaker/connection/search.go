@@ -0,0 +1,205 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	searchesKey         = "matchmaking:searches"
+	ownerKeyFmt         = "matchmaking:owner:%s"
+	podKeyFmt           = "matchmaking:pod:%s"
+	podNotificationsFmt = "matchmaking:pod:%s:notifications"
+	answersChannel      = "matchmaking:answers"
+)
+
+// notification is the published form of types.Notification, the error of
+// a response stays on the replica that produced it.
+type notification struct {
+	PlayerIds []string         `json:"player_ids"`
+	Status    types.ItemStatus `json:"status"`
+	RoomId    string           `json:"room_id,omitempty"`
+	GroupSize int              `json:"group_size,omitempty"`
+	Deadline  time.Time        `json:"deadline,omitzero"`
+	Requeued  bool             `json:"requeued,omitempty"`
+}
+
+type searchRepository struct {
+	client *redis.Client
+}
+
+func NewSearchRepository(client *redis.Client) repositories.SearchRepository {
+	return &searchRepository{client: client}
+}
+
+func (r *searchRepository) SaveSearch(ctx context.Context, search types.MatchChan) error {
+	value, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, searchesKey, search.PlayerId, value).Err()
+}
+
+func (r *searchRepository) DeleteSearch(ctx context.Context, playerId string) error {
+	return r.client.HDel(ctx, searchesKey, playerId).Err()
+}
+
+func (r *searchRepository) GetSearch(ctx context.Context, playerId string) (types.MatchChan, error) {
+	value, err := r.client.HGet(ctx, searchesKey, playerId).Bytes()
+	if err != nil {
+		return types.MatchChan{}, err
+	}
+
+	var search types.MatchChan
+	err = json.Unmarshal(value, &search)
+	return search, err
+}
+
+func (r *searchRepository) ListSearches(ctx context.Context) ([]types.MatchChan, error) {
+	values, err := r.client.HGetAll(ctx, searchesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]types.MatchChan, 0, len(values))
+	for _, value := range values {
+		var search types.MatchChan
+		if err := json.Unmarshal([]byte(value), &search); err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, nil
+}
+
+func (r *searchRepository) SetOwner(ctx context.Context, playerId string, pod string) error {
+	return r.client.Set(ctx, fmt.Sprintf(ownerKeyFmt, playerId), pod, playerTTL).Err()
+}
+
+func (r *searchRepository) DeleteOwner(ctx context.Context, playerId string) error {
+	return r.client.Del(ctx, fmt.Sprintf(ownerKeyFmt, playerId)).Err()
+}
+
+func (r *searchRepository) Heartbeat(ctx context.Context, pod string, ttl time.Duration) error {
+	return r.client.Set(ctx, fmt.Sprintf(podKeyFmt, pod), 1, ttl).Err()
+}
+
+func (r *searchRepository) IsAlive(ctx context.Context, pod string) (bool, error) {
+	count, err := r.client.Exists(ctx, fmt.Sprintf(podKeyFmt, pod)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *searchRepository) Notify(ctx context.Context, playerIds []string, response types.MatchResponse) error {
+	if len(playerIds) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(playerIds))
+	for i, playerId := range playerIds {
+		keys[i] = fmt.Sprintf(ownerKeyFmt, playerId)
+	}
+	owners, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+
+	// players without an owner have left, nobody is waiting for them
+	byPod := make(map[string][]string)
+	for i, owner := range owners {
+		if pod, ok := owner.(string); ok {
+			byPod[pod] = append(byPod[pod], playerIds[i])
+		}
+	}
+
+	for pod, players := range byPod {
+		value, err := json.Marshal(notification{
+			PlayerIds: players,
+			Status:    response.Status,
+			RoomId:    response.RoomId,
+			GroupSize: response.GroupSize,
+			Deadline:  response.Deadline,
+			Requeued:  response.Requeued,
+		})
+		if err != nil {
+			return err
+		}
+		err = r.client.Publish(ctx, fmt.Sprintf(podNotificationsFmt, pod), value).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *searchRepository) Subscribe(ctx context.Context, pod string) (<-chan types.Notification, func() error, error) {
+	return subscribe(ctx, r.client, fmt.Sprintf(podNotificationsFmt, pod), func(n notification) types.Notification {
+		return types.Notification{
+			PlayerIds: n.PlayerIds,
+			Response: types.MatchResponse{
+				Status:    n.Status,
+				RoomId:    n.RoomId,
+				GroupSize: n.GroupSize,
+				Deadline:  n.Deadline,
+				Requeued:  n.Requeued,
+			},
+		}
+	})
+}
+
+func (r *searchRepository) PublishAnswer(ctx context.Context, answer types.MatchAccept) error {
+	value, err := json.Marshal(answer)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, answersChannel, value).Err()
+}
+
+func (r *searchRepository) SubscribeAnswers(ctx context.Context) (<-chan types.MatchAccept, func() error, error) {
+	return subscribe(ctx, r.client, answersChannel, func(answer types.MatchAccept) types.MatchAccept {
+		return answer
+	})
+}
+
+// subscribe decodes the messages of the channel as M and delivers them
+// converted until close is called.
+func subscribe[M, T any](ctx context.Context, client *redis.Client, channel string, convert func(M) T) (<-chan T, func() error, error) {
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, err
+	}
+
+	values := make(chan T)
+	done := make(chan struct{})
+	go func() {
+		defer close(values)
+		for message := range pubsub.Channel() {
+			var decoded M
+			if err := json.Unmarshal([]byte(message.Payload), &decoded); err != nil {
+				continue
+			}
+			select {
+			case values <- convert(decoded):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	closeSubscription := func() error {
+		once.Do(func() { close(done) })
+		return pubsub.Close()
+	}
+	return values, closeSubscription, nil
+}
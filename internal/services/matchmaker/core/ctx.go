@@ -2,12 +2,12 @@ package core
 
 import (
 	"log/slog"
-	"os"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/connection"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/infra/config"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
 )
 
 type Ctx struct {
@@ -38,10 +38,18 @@ func (c *Ctx) Make() domain.Context {
 
 func InitCtx() *Ctx {
 	cfg := config.Make()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger, err := logging.New("matchmaker", cfg.GetLogLevel())
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
 
 	conn := connection.Make(cfg)
 
+	return NewCtx(cfg, logger, conn)
+}
+
+func NewCtx(cfg infra.Config, logger *slog.Logger, conn domain.Connection) *Ctx {
 	return &Ctx{
 		cfg:    cfg,
 		logger: logger,
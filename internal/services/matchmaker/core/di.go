@@ -12,9 +12,12 @@ import (
 type Di struct {
 	Ctx               domain.Context
 	MatchmakerUseCase *cases.MatchmakerUseCase
+	PartyUseCase      *cases.PartyUseCase
+	LobbyUseCase      *cases.LobbyUseCase
 	Handler           *http.Handler
 	QueueChan         chan types.MatchChan
 	CancelChan        chan types.MatchCancel
+	AcceptChan        chan types.MatchAccept
 	PlayersClient     players.PlayersClient
 	GameClient        game.GameClient
 }
@@ -26,27 +29,38 @@ func NewDi() *Di {
 
 	queueChan := make(chan types.MatchChan)
 	cancelChan := make(chan types.MatchCancel)
+	acceptChan := make(chan types.MatchAccept)
 
 	matchmakerUseCase := cases.NewMatchmakerUseCase(
 		ctx,
 		queueChan,
 		cancelChan,
+		acceptChan,
 		gameClient,
 	)
 
+	partyUseCase := cases.NewPartyUseCase(ctx)
+	lobbyUseCase := cases.NewLobbyUseCase(ctx, gameClient)
+
 	handler := http.NewHandler(
 		queueChan,
 		cancelChan,
+		acceptChan,
 		ctx,
 		playersClient,
+		partyUseCase,
+		lobbyUseCase,
 	)
 
 	return &Di{
 		Ctx:               ctx,
 		MatchmakerUseCase: matchmakerUseCase,
+		PartyUseCase:      partyUseCase,
+		LobbyUseCase:      lobbyUseCase,
 		Handler:           handler,
 		QueueChan:         queueChan,
 		CancelChan:        cancelChan,
+		AcceptChan:        acceptChan,
 		PlayersClient:     playersClient,
 		GameClient:        gameClient,
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
 	"github.com/MommusWinner/MicroDurak/internal/contracts/players/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/infra"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -26,7 +27,7 @@ func makeSecureConnection(url string) (*grpc.ClientConn, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.DialContext(ctx, url, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to grpc client: %v", err)
 	}
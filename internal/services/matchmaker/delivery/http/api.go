@@ -5,12 +5,33 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/MommusWinner/MicroDurak/lib/jwt"
+	"github.com/MommusWinner/MicroDurak/lib/ticket"
 )
 
 func AddRoutes(
 	e *echo.Echo,
 	handler *Handler,
 ) {
-	e.GET("/api/v1/matchmaker/find-match", handler.FindMatch, jwt.AuthMiddleware(handler.Ctx.Config().GetJWTPublic()))
+	tickets := jwt.WithTickets(func(c echo.Context, t string) (string, error) {
+		return handler.Ctx.Connection().Tickets().Consume(c.Request().Context(), t, ticket.ScopeMatchmaker)
+	})
+	e.GET("/api/v1/matchmaker/find-match", handler.FindMatch, jwt.AuthMiddleware(handler.Ctx.Config().GetJWTPublic(), tickets))
+
+	party := e.Group("/api/v1/matchmaker/party", jwt.AuthMiddleware(handler.Ctx.Config().GetJWTPublic()))
+	party.POST("", handler.CreateParty)
+	party.GET("", handler.GetParty)
+	party.POST("/invite", handler.InviteToParty)
+	party.POST("/:partyId/accept", handler.AcceptParty)
+	party.POST("/leave", handler.LeaveParty)
+
+	lobbies := e.Group("/api/v1/matchmaker/lobbies", jwt.AuthMiddleware(handler.Ctx.Config().GetJWTPublic()))
+	lobbies.POST("", handler.CreateLobby)
+	lobbies.GET("", handler.ListLobbies)
+	lobbies.GET("/:code", handler.GetLobby)
+	lobbies.POST("/:code/join", handler.JoinLobby)
+	lobbies.POST("/:code/ready", handler.SetLobbyReady)
+	lobbies.POST("/:code/leave", handler.LeaveLobby)
+	lobbies.POST("/:code/start", handler.StartLobby)
+	e.GET("/api/v1/matchmaker/lobbies/:code/events", handler.LobbyEvents, jwt.AuthMiddleware(handler.Ctx.Config().GetJWTPublic(), tickets))
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 }
@@ -22,7 +22,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Initiates a WebSocket connection for players looking for a match",
+                "description": "Initiates a WebSocket connection for players looking for a match.\nThe leader of a party searches for the whole party, the other\nmembers receive the responses of that search. Every player\nanswers match_found with {\"action\": \"accept\"} or {\"action\": \"decline\"}\nbefore its deadline, declining keeps the player out of the queue for a while.\nWhen a member of a party leaves, the search of the party ends with \"cancelled\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,18 +33,732 @@ const docTemplate = `{
                     "matchmaker"
                 ],
                 "summary": "Find a match via WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Single-use ticket from the auth service, used instead of the JWT",
+                        "name": "ticket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Matchmaking mode, e.g. classic-2p, defaults to the server default",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Exact table size within the sizes of the mode",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Smallest table accepted after waiting, defaults to the smallest of the mode",
+                        "name": "min_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Table size to fill, defaults to the largest of the mode",
+                        "name": "max_size",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "101": {
                         "description": "WebSocket upgrade successful"
                     },
+                    "400": {
+                        "description": "Unknown mode, invalid table size or party too large"
+                    },
                     "401": {
                         "description": "Unauthorized - Invalid JWT token or unknown player"
                     },
+                    "429": {
+                        "description": "Declined a match recently"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the newest public lobbies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "List lobbies",
+                "responses": {
+                    "200": {
+                        "description": "Public lobbies",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.LobbiesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Opens a lobby with the given game settings, others join it by its invite code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Create a lobby",
+                "parameters": [
+                    {
+                        "description": "Game settings, zero values use the defaults",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.CreateLobbyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created lobby",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid settings, e.g. a deck too small to deal six cards to every player"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Already in a lobby"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the lobby of the invite code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Get a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lobby",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends the lobby on connect and every change after it. The\nconnection is closed once the game started or the lobby closed.",
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Watch a lobby via WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Single-use ticket from the auth service, used instead of the JWT",
+                        "name": "ticket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "WebSocket upgrade successful"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not in the lobby"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}/join": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Joins the lobby of the invite code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Join a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Joined lobby",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "409": {
+                        "description": "Already in a lobby, lobby full or starting"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}/leave": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leaves the lobby, a host hands the lobby to the next member",
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Leave a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Left the lobby"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not in the lobby"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}/ready": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks the player of the lobby as ready or not ready",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Ready up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ready state",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.ReadyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated lobby",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not in the lobby"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "409": {
+                        "description": "Lobby starting"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/lobbies/{code}/start": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The host starts an unranked game once everyone in the lobby is ready",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Start the game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created game",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.StartLobbyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Not every player is ready"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not the host"
+                    },
+                    "404": {
+                        "description": "Lobby not found"
+                    },
+                    "409": {
+                        "description": "Lobby starting"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/party": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the party the player is in",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "party"
+                ],
+                "summary": "Get the party",
+                "responses": {
+                    "200": {
+                        "description": "Party of the player",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.PartyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not in a party"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a party led by the player, who may then invite others",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "party"
+                ],
+                "summary": "Create a party",
+                "responses": {
+                    "201": {
+                        "description": "Created party",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.PartyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Already in a party"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/party/invite": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The party leader invites a player by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "party"
+                ],
+                "summary": "Invite to the party",
+                "parameters": [
+                    {
+                        "description": "Invited player",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.InviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Player invited"
+                    },
+                    "400": {
+                        "description": "Missing player id"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not the party leader"
+                    },
+                    "404": {
+                        "description": "Not in a party"
+                    },
+                    "409": {
+                        "description": "Party is full"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
             }
+        },
+        "/matchmaker/party/leave": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leaves the party, a leader hands the party to the next member.\nA running search of the party ends with \"cancelled\".",
+                "tags": [
+                    "party"
+                ],
+                "summary": "Leave the party",
+                "responses": {
+                    "204": {
+                        "description": "Left the party"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not in a party"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/matchmaker/party/{partyId}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Joins the party the player was invited to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "party"
+                ],
+                "summary": "Accept a party invite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Party id",
+                        "name": "partyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Joined party",
+                        "schema": {
+                            "$ref": "#/definitions/delivery_http.PartyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Not invited"
+                    },
+                    "409": {
+                        "description": "Already in a party or party is full"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "delivery_http.CreateLobbyRequest": {
+            "type": "object",
+            "properties": {
+                "deck_size": {
+                    "type": "integer"
+                },
+                "max_players": {
+                    "type": "integer"
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "delivery_http.InviteRequest": {
+            "type": "object",
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery_http.LobbiesResponse": {
+            "type": "object",
+            "properties": {
+                "lobbies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby"
+                    }
+                }
+            }
+        },
+        "delivery_http.PartyResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "leader_id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "delivery_http.ReadyRequest": {
+            "type": "object",
+            "properties": {
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "delivery_http.StartLobbyResponse": {
+            "type": "object",
+            "properties": {
+                "game_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.Lobby": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.LobbyMember"
+                    }
+                },
+                "settings": {
+                    "$ref": "#/definitions/github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.LobbySettings"
+                },
+                "starting": {
+                    "description": "Starting is set while the game of the lobby is created.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.LobbyMember": {
+            "type": "object",
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_MommusWinner_MicroDurak_internal_services_matchmaker_domain_models.LobbySettings": {
+            "type": "object",
+            "properties": {
+                "deck_size": {
+                    "type": "integer"
+                },
+                "max_players": {
+                    "type": "integer"
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
         }
     }
 }`
@@ -2,61 +2,109 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/contracts/players/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/cases"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/metrics"
+	"github.com/MommusWinner/MicroDurak/lib/origin"
+	"github.com/MommusWinner/MicroDurak/lib/ratelimit"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-var (
-	upgrader = websocket.Upgrader{}
+// SubprotocolMatchmaker is selected when the client offers it, e.g. next to a
+// token passed as a subprotocol.
+const SubprotocolMatchmaker = "durak.matchmaker.v1"
+
+// statusRateLimited tells the client that its message was dropped.
+const statusRateLimited = "rate_limited"
+
+// Answers to match_found sent by the client.
+const (
+	actionAccept  = "accept"
+	actionDecline = "decline"
 )
 
+type FindMatchMessage struct {
+	Action string `json:"action"`
+}
+
 type FindMatchResponse struct {
 	// MatchStatus string
 	Status    string `json:"status"`
 	GameId    string `json:"game_id,omitempty"`
 	GroupSize int    `json:"group_size,omitzero"`
+	// Deadline to accept a found match.
+	Deadline time.Time `json:"deadline,omitzero"`
+	// Requeued is set on an error after which the search goes on.
+	Requeued bool `json:"requeued,omitempty"`
 }
 
 type Handler struct {
 	Queue         chan<- types.MatchChan
 	Cancel        chan<- types.MatchCancel
+	Accept        chan<- types.MatchAccept
 	Ctx           domain.Context
 	PlayersClient players.PlayersClient
+	Parties       *cases.PartyUseCase
+	Lobbies       *cases.LobbyUseCase
+	upgrader      websocket.Upgrader
 }
 
 func NewHandler(
 	queue chan<- types.MatchChan,
 	cancel chan<- types.MatchCancel,
+	accept chan<- types.MatchAccept,
 	ctx domain.Context,
 	playersClient players.PlayersClient,
+	parties *cases.PartyUseCase,
+	lobbies *cases.LobbyUseCase,
 ) *Handler {
 	return &Handler{
 		Queue:         queue,
 		Cancel:        cancel,
+		Accept:        accept,
 		Ctx:           ctx,
 		PlayersClient: playersClient,
+		Parties:       parties,
+		Lobbies:       lobbies,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{SubprotocolMatchmaker},
+			CheckOrigin:  origin.Checker(ctx.Config().GetAllowedOrigins()),
+		},
 	}
 }
 
 // FindMatch handles WebSocket connections for players looking for matches
 // @Summary Find a match via WebSocket
-// @Description Initiates a WebSocket connection for players looking for a match
+// @Description Initiates a WebSocket connection for players looking for a match.
+// @Description The leader of a party searches for the whole party, the other
+// @Description members receive the responses of that search. Every player
+// @Description answers match_found with {"action": "accept"} or {"action": "decline"}
+// @Description before its deadline, declining keeps the player out of the queue for a while.
+// @Description When a member of a party leaves, the search of the party ends with "cancelled".
 // @Tags matchmaker
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param ticket query string false "Single-use ticket from the auth service, used instead of the JWT"
+// @Param mode query string false "Matchmaking mode, e.g. classic-2p, defaults to the server default"
+// @Param size query int false "Exact table size within the sizes of the mode"
+// @Param min_size query int false "Smallest table accepted after waiting, defaults to the smallest of the mode"
+// @Param max_size query int false "Table size to fill, defaults to the largest of the mode"
 // @Success 101 "WebSocket upgrade successful"
+// @Failure 400 "Unknown mode, invalid table size or party too large"
 // @Failure 401 "Unauthorized - Invalid JWT token or unknown player"
+// @Failure 429 "Declined a match recently"
 // @Failure 500 "Internal server error"
 // @Router /matchmaker/find-match [get]
 func (h *Handler) FindMatch(c echo.Context) error {
@@ -85,31 +133,30 @@ func (h *Handler) FindMatch(c echo.Context) error {
 		).Inc()
 	}()
 
-	ctx := c.Request().Context()
-
 	playerId, ok := c.Get("playerId").(string)
 	if !ok {
 		panic("Missing jwt middleware")
 	}
 
-	player, err := h.PlayersClient.GetPlayer(ctx, &players.GetPlayerRequest{Id: playerId})
+	request, err := h.matchRequest(c, playerId)
 	if err != nil {
-		s := status.Convert(err)
-		if s.Code() == codes.NotFound {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Unknown Player")
-		}
-		c.Logger().Error(err)
 		return err
 	}
 
-	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
 		metrics.WebsocketUpgradeErrors.WithLabelValues(h.Ctx.Config().GetPodName(), h.Ctx.Config().GetNamespace()).Inc()
 		return err
 	}
 	defer ws.Close()
 
+	cfg := h.Ctx.Config()
+	ws.SetReadLimit(int64(cfg.GetMaxFrameSize()))
+	touch, stopKeepAlive := h.keepAlive(ws)
+	defer stopKeepAlive()
+
 	doneChan := make(chan bool, 1)
+	rejectChan := make(chan struct{}, 1)
 	closeHandler := ws.CloseHandler()
 	ws.SetCloseHandler(func(code int, text string) error {
 		select {
@@ -127,23 +174,50 @@ func (h *Handler) FindMatch(c echo.Context) error {
 			default:
 			}
 		}()
+		guard := ratelimit.NewGuard(cfg.GetMessageBurst(), cfg.GetMessageInterval(), cfg.GetMaxViolations())
 		for {
-			if _, _, err := ws.ReadMessage(); err != nil {
+			_, data, err := ws.ReadMessage()
+			if errors.Is(err, websocket.ErrReadLimit) {
+				metrics.WebsocketViolations.WithLabelValues(cfg.GetPodName(), cfg.GetNamespace(), "frame_too_large").Inc()
+				break
+			}
+			if err != nil {
 				break
 			}
+			touch()
+
+			switch guard.Check() {
+			case ratelimit.Reject:
+				metrics.WebsocketViolations.WithLabelValues(cfg.GetPodName(), cfg.GetNamespace(), "rate_limited").Inc()
+				select {
+				case rejectChan <- struct{}{}:
+				default:
+				}
+			case ratelimit.Disconnect:
+				metrics.WebsocketViolations.WithLabelValues(cfg.GetPodName(), cfg.GetNamespace(), "rate_limited").Inc()
+				metrics.WebsocketAbuseDisconnects.WithLabelValues(cfg.GetPodName(), cfg.GetNamespace()).Inc()
+				closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Rate limit exceeded")
+				ws.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+				return
+			default:
+				var message FindMatchMessage
+				if json.Unmarshal(data, &message) != nil {
+					continue
+				}
+				if message.Action == actionAccept || message.Action == actionDecline {
+					h.Accept <- types.MatchAccept{PlayerId: playerId, Accept: message.Action == actionAccept}
+				}
+			}
 		}
 	}()
 
 	metrics.PlayersSearching.WithLabelValues(h.Ctx.Config().GetPodName(), h.Ctx.Config().GetNamespace()).Inc()
 	defer metrics.PlayersSearching.WithLabelValues(h.Ctx.Config().GetPodName(), h.Ctx.Config().GetNamespace()).Dec()
 
-	returnChan := make(chan types.MatchResponse)
-	h.Queue <- types.MatchChan{
-		PlayerId:   playerId,
-		Rating:     int(player.Rating),
-		SentTime:   time.Now(),
-		ReturnChan: returnChan,
-	}
+	returnChan := make(chan types.MatchResponse, cfg.GetSendBufferSize())
+	request.SentTime = time.Now()
+	request.ReturnChan = returnChan
+	h.Queue <- request
 
 	for {
 		select {
@@ -152,8 +226,9 @@ func (h *Handler) FindMatch(c echo.Context) error {
 			case types.MatchCreated:
 				roomId := matchReturn.RoomId
 				resp := FindMatchResponse{
-					Status: types.MatchCreated.String(),
-					GameId: roomId,
+					Status:    types.MatchCreated.String(),
+					GameId:    roomId,
+					GroupSize: matchReturn.GroupSize,
 				}
 
 				respString, _ := json.Marshal(resp)
@@ -166,23 +241,50 @@ func (h *Handler) FindMatch(c echo.Context) error {
 				}
 
 				return nil
-			case types.MatchError:
+			case types.MatchDeclined:
 				status := FindMatchResponse{
 					Status: matchReturn.Status.String(),
 				}
 
+				statusString, _ := json.Marshal(status)
+				ws.WriteMessage(websocket.TextMessage, statusString)
+
+				closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Match declined")
+				ws.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+				return nil
+			case types.MatchCancelled:
+				status := FindMatchResponse{
+					Status: matchReturn.Status.String(),
+				}
+
+				statusString, _ := json.Marshal(status)
+				ws.WriteMessage(websocket.TextMessage, statusString)
+
+				closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Party left the search")
+				ws.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+				return nil
+			case types.MatchError:
+				status := FindMatchResponse{
+					Status:   matchReturn.Status.String(),
+					Requeued: matchReturn.Requeued,
+				}
+
 				stautsString, _ := json.Marshal(status)
 
-				ws.WriteMessage(websocket.TextMessage, stautsString)
+				err := ws.WriteMessage(websocket.TextMessage, stautsString)
 				if err != nil {
 					return err
 				}
+				if matchReturn.Requeued {
+					continue
+				}
 
 				return matchReturn.Error
 			default:
 				status := FindMatchResponse{
 					Status:    matchReturn.Status.String(),
 					GroupSize: matchReturn.GroupSize,
+					Deadline:  matchReturn.Deadline,
 				}
 
 				stautsString, _ := json.Marshal(status)
@@ -192,9 +294,121 @@ func (h *Handler) FindMatch(c echo.Context) error {
 					return err
 				}
 			}
+		case <-rejectChan:
+			status := FindMatchResponse{
+				Status: statusRateLimited,
+			}
+
+			statusString, _ := json.Marshal(status)
+
+			err := ws.WriteMessage(websocket.TextMessage, statusString)
+			if err != nil {
+				return err
+			}
 		case <-doneChan:
-			h.Cancel <- types.MatchCancel{PlayerId: playerId}
+			h.Cancel <- types.MatchCancel{PlayerId: playerId, Leader: request.Leader}
 			return nil
 		}
 	}
 }
+
+// matchRequest builds the search of the player. Members of a party follow
+// the search of their leader, the leader searches with the combined rating
+// of the party.
+func (h *Handler) matchRequest(c echo.Context, playerId string) (types.MatchChan, error) {
+	ctx := c.Request().Context()
+
+	party, err := h.Parties.GetParty(ctx, playerId)
+	if errors.Is(err, cases.ErrNotInParty) {
+		party = models.Party{LeaderId: playerId, Members: []string{playerId}}
+	} else if err != nil {
+		h.Ctx.Logger().ErrorContext(ctx, "Failed to get party", "player_id", playerId, "error", err.Error())
+		return types.MatchChan{}, err
+	}
+
+	for _, member := range party.Members {
+		left, err := h.Ctx.Connection().PenaltyRepository().PenaltyLeft(ctx, member)
+		if err != nil {
+			h.Ctx.Logger().ErrorContext(ctx, "Failed to get queue penalty", "player_id", member, "error", err.Error())
+			return types.MatchChan{}, err
+		}
+		if left > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(left.Seconds())+1))
+			return types.MatchChan{}, echo.NewHTTPError(http.StatusTooManyRequests, "Declined a match recently")
+		}
+	}
+
+	if party.LeaderId != playerId {
+		return types.MatchChan{PlayerId: playerId, Leader: party.LeaderId}, nil
+	}
+
+	mode, err := cases.ModeByName(h.Ctx.Config(), c.QueryParam("mode"))
+	if err != nil {
+		return types.MatchChan{}, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	minSize, maxSize, err := tableSize(c, mode)
+	if err != nil {
+		return types.MatchChan{}, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// the party has to leave a seat for at least one other player
+	if len(party.Members) >= maxSize {
+		return types.MatchChan{}, echo.NewHTTPError(http.StatusBadRequest, cases.ErrPartyTooLarge.Error())
+	}
+
+	ratings := make([]int, 0, len(party.Members))
+	for _, member := range party.Members {
+		player, err := h.PlayersClient.GetPlayer(ctx, &players.GetPlayerRequest{Id: member})
+		if err != nil {
+			s := status.Convert(err)
+			if s.Code() == codes.NotFound && member == playerId {
+				return types.MatchChan{}, echo.NewHTTPError(http.StatusUnauthorized, "Unknown Player")
+			}
+			h.Ctx.Logger().ErrorContext(ctx, "Failed to get player", "player_id", member, "error", err.Error())
+			return types.MatchChan{}, err
+		}
+		ratings = append(ratings, int(player.Rating))
+	}
+
+	return types.MatchChan{
+		PlayerId: playerId,
+		Mode:     mode.Name,
+		Rating:   cases.PartyRating(h.Ctx.Config().GetPartyRating(), ratings),
+		MinSize:  minSize,
+		MaxSize:  maxSize,
+		Party:    party.Members[1:],
+	}, nil
+}
+
+// tableSize reads the requested table size, either an exact size or a range
+// the table fills up to max_size and may start at min_size. Both default to
+// the sizes of mode and must stay within them.
+func tableSize(c echo.Context, mode types.Mode) (minSize int, maxSize int, err error) {
+	minSize, maxSize = mode.MinSize, mode.MaxSize
+	if size := c.QueryParam("size"); size != "" {
+		maxSize, err = strconv.Atoi(size)
+		if err != nil {
+			return 0, 0, cases.ErrInvalidTableSize
+		}
+		minSize = maxSize
+	}
+
+	if size := c.QueryParam("max_size"); size != "" {
+		maxSize, err = strconv.Atoi(size)
+		if err != nil {
+			return 0, 0, cases.ErrInvalidTableSize
+		}
+	}
+
+	if size := c.QueryParam("min_size"); size != "" {
+		minSize, err = strconv.Atoi(size)
+		if err != nil {
+			return 0, 0, cases.ErrInvalidTableSize
+		}
+	}
+
+	if minSize < mode.MinSize || maxSize > mode.MaxSize {
+		return 0, 0, cases.ErrInvalidTableSize
+	}
+	return minSize, maxSize, cases.ValidateTableSize(minSize, maxSize)
+}
@@ -0,0 +1,296 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/cases"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+type CreateLobbyRequest struct {
+	MaxPlayers int  `json:"max_players"`
+	DeckSize   int  `json:"deck_size"`
+	Public     bool `json:"public"`
+}
+
+type ReadyRequest struct {
+	Ready bool `json:"ready"`
+}
+
+type StartLobbyResponse struct {
+	GameId string `json:"game_id"`
+}
+
+type LobbiesResponse struct {
+	Lobbies []models.Lobby `json:"lobbies"`
+}
+
+// lobbyError maps the errors of the lobby use case to HTTP errors.
+func (h *Handler) lobbyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, cases.ErrLobbyNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, cases.ErrInvalidLobbySettings), errors.Is(err, cases.ErrLobbyNotReady):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, cases.ErrNotLobbyHost), errors.Is(err, cases.ErrNotInLobby):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, cases.ErrAlreadyInLobby), errors.Is(err, cases.ErrLobbyFull), errors.Is(err, cases.ErrLobbyStarting):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	h.Ctx.Logger().ErrorContext(c.Request().Context(), "Lobby request failed", "error", err.Error())
+	return internalServerError
+}
+
+// CreateLobby opens a lobby hosted by the player
+// @Summary Create a lobby
+// @Description Opens a lobby with the given game settings, others join it by its invite code
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateLobbyRequest true "Game settings, zero values use the defaults"
+// @Success 201 {object} models.Lobby "Created lobby"
+// @Failure 400 "Invalid settings, e.g. a deck too small to deal six cards to every player"
+// @Failure 401 "Unauthorized"
+// @Failure 409 "Already in a lobby"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies [post]
+func (h *Handler) CreateLobby(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	r := new(CreateLobbyRequest)
+	if err := c.Bind(r); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	lobby, err := h.Lobbies.CreateLobby(c.Request().Context(), playerId, models.LobbySettings{
+		MaxPlayers: r.MaxPlayers,
+		DeckSize:   r.DeckSize,
+		Public:     r.Public,
+	})
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, lobby)
+}
+
+// ListLobbies lists the public lobbies
+// @Summary List lobbies
+// @Description Returns the newest public lobbies
+// @Tags lobby
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} LobbiesResponse "Public lobbies"
+// @Failure 401 "Unauthorized"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies [get]
+func (h *Handler) ListLobbies(c echo.Context) error {
+	lobbies, err := h.Lobbies.ListLobbies(c.Request().Context())
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, LobbiesResponse{Lobbies: lobbies})
+}
+
+// GetLobby returns the lobby of an invite code
+// @Summary Get a lobby
+// @Description Returns the lobby of the invite code
+// @Tags lobby
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Success 200 {object} models.Lobby "Lobby"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Lobby not found"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code} [get]
+func (h *Handler) GetLobby(c echo.Context) error {
+	lobby, err := h.Lobbies.GetLobby(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, lobby)
+}
+
+// JoinLobby adds the player to a lobby
+// @Summary Join a lobby
+// @Description Joins the lobby of the invite code
+// @Tags lobby
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Success 200 {object} models.Lobby "Joined lobby"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Lobby not found"
+// @Failure 409 "Already in a lobby, lobby full or starting"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code}/join [post]
+func (h *Handler) JoinLobby(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	lobby, err := h.Lobbies.Join(c.Request().Context(), playerId, c.Param("code"))
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, lobby)
+}
+
+// SetLobbyReady marks the player ready or not
+// @Summary Ready up
+// @Description Marks the player of the lobby as ready or not ready
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Param request body ReadyRequest true "Ready state"
+// @Success 200 {object} models.Lobby "Updated lobby"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not in the lobby"
+// @Failure 404 "Lobby not found"
+// @Failure 409 "Lobby starting"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code}/ready [post]
+func (h *Handler) SetLobbyReady(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	r := new(ReadyRequest)
+	if err := c.Bind(r); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	lobby, err := h.Lobbies.SetReady(c.Request().Context(), playerId, c.Param("code"), r.Ready)
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, lobby)
+}
+
+// LeaveLobby takes the player out of a lobby
+// @Summary Leave a lobby
+// @Description Leaves the lobby, a host hands the lobby to the next member
+// @Tags lobby
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Success 204 "Left the lobby"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not in the lobby"
+// @Failure 404 "Lobby not found"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code}/leave [post]
+func (h *Handler) LeaveLobby(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	err := h.Lobbies.Leave(c.Request().Context(), playerId, c.Param("code"))
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// StartLobby creates the game of the lobby
+// @Summary Start the game
+// @Description The host starts an unranked game once everyone in the lobby is ready
+// @Tags lobby
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Success 200 {object} StartLobbyResponse "Created game"
+// @Failure 400 "Not every player is ready"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not the host"
+// @Failure 404 "Lobby not found"
+// @Failure 409 "Lobby starting"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code}/start [post]
+func (h *Handler) StartLobby(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	gameId, err := h.Lobbies.Start(c.Request().Context(), playerId, c.Param("code"))
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, StartLobbyResponse{GameId: gameId})
+}
+
+// LobbyEvents streams the events of a lobby
+// @Summary Watch a lobby via WebSocket
+// @Description Sends the lobby on connect and every change after it. The
+// @Description connection is closed once the game started or the lobby closed.
+// @Tags lobby
+// @Security BearerAuth
+// @Param code path string true "Invite code"
+// @Param ticket query string false "Single-use ticket from the auth service, used instead of the JWT"
+// @Success 101 "WebSocket upgrade successful"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not in the lobby"
+// @Failure 404 "Lobby not found"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/lobbies/{code}/events [get]
+func (h *Handler) LobbyEvents(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+	ctx := c.Request().Context()
+
+	lobby, events, closeEvents, err := h.Lobbies.Watch(ctx, playerId, c.Param("code"))
+	if err != nil {
+		return h.lobbyError(c, err)
+	}
+	defer closeEvents()
+
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	ws.SetReadLimit(int64(h.Ctx.Config().GetMaxFrameSize()))
+	touch, stopKeepAlive := h.keepAlive(ws)
+	defer stopKeepAlive()
+
+	// the client only listens, reading notices when it goes away
+	doneChan := make(chan struct{})
+	go func() {
+		defer close(doneChan)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+			touch()
+		}
+	}()
+
+	event := models.LobbyEvent{Type: models.LobbyUpdated, Lobby: lobby}
+	for {
+		message, _ := json.Marshal(event)
+		if err := ws.WriteMessage(websocket.TextMessage, message); err != nil {
+			return nil
+		}
+
+		if event.Type != models.LobbyUpdated {
+			closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, event.Type)
+			ws.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+			return nil
+		}
+
+		var ok bool
+		select {
+		case event, ok = <-events:
+			if !ok {
+				return nil
+			}
+		case <-doneChan:
+			return nil
+		}
+	}
+}
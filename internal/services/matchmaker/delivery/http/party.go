@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/cases"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/labstack/echo/v4"
+)
+
+type PartyResponse struct {
+	Id       string   `json:"id"`
+	LeaderId string   `json:"leader_id"`
+	Members  []string `json:"members"`
+}
+
+type InviteRequest struct {
+	PlayerId string `json:"player_id"`
+}
+
+var internalServerError = echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+
+func partyResponse(party models.Party) PartyResponse {
+	return PartyResponse{
+		Id:       party.Id,
+		LeaderId: party.LeaderId,
+		Members:  party.Members,
+	}
+}
+
+// partyError maps the errors of the party use case to HTTP errors.
+func (h *Handler) partyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, cases.ErrNotInParty):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, cases.ErrNotPartyLeader), errors.Is(err, cases.ErrNotInvited):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, cases.ErrAlreadyInParty), errors.Is(err, cases.ErrPartyFull):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	h.Ctx.Logger().ErrorContext(c.Request().Context(), "Party request failed", "error", err.Error())
+	return internalServerError
+}
+
+// CreateParty makes the player the leader of a new party
+// @Summary Create a party
+// @Description Creates a party led by the player, who may then invite others
+// @Tags party
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} PartyResponse "Created party"
+// @Failure 401 "Unauthorized"
+// @Failure 409 "Already in a party"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/party [post]
+func (h *Handler) CreateParty(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	party, err := h.Parties.CreateParty(c.Request().Context(), playerId)
+	if err != nil {
+		return h.partyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, partyResponse(party))
+}
+
+// GetParty returns the party of the player
+// @Summary Get the party
+// @Description Returns the party the player is in
+// @Tags party
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} PartyResponse "Party of the player"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Not in a party"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/party [get]
+func (h *Handler) GetParty(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	party, err := h.Parties.GetParty(c.Request().Context(), playerId)
+	if err != nil {
+		return h.partyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, partyResponse(party))
+}
+
+// InviteToParty invites another player into the party of the leader
+// @Summary Invite to the party
+// @Description The party leader invites a player by id
+// @Tags party
+// @Accept json
+// @Security BearerAuth
+// @Param request body InviteRequest true "Invited player"
+// @Success 204 "Player invited"
+// @Failure 400 "Missing player id"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not the party leader"
+// @Failure 404 "Not in a party"
+// @Failure 409 "Party is full"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/party/invite [post]
+func (h *Handler) InviteToParty(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	r := new(InviteRequest)
+	if err := c.Bind(r); err != nil || r.PlayerId == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "player_id is required")
+	}
+
+	err := h.Parties.Invite(c.Request().Context(), playerId, r.PlayerId)
+	if err != nil {
+		return h.partyError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AcceptParty joins a party the player was invited to
+// @Summary Accept a party invite
+// @Description Joins the party the player was invited to
+// @Tags party
+// @Produce json
+// @Security BearerAuth
+// @Param partyId path string true "Party id"
+// @Success 200 {object} PartyResponse "Joined party"
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Not invited"
+// @Failure 409 "Already in a party or party is full"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/party/{partyId}/accept [post]
+func (h *Handler) AcceptParty(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	party, err := h.Parties.Accept(c.Request().Context(), playerId, c.Param("partyId"))
+	if err != nil {
+		return h.partyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, partyResponse(party))
+}
+
+// LeaveParty takes the player out of its party
+// @Summary Leave the party
+// @Description Leaves the party, a leader hands the party to the next member.
+// @Description A running search of the party ends with "cancelled".
+// @Tags party
+// @Security BearerAuth
+// @Success 204 "Left the party"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Not in a party"
+// @Failure 500 "Internal server error"
+// @Router /matchmaker/party/leave [post]
+func (h *Handler) LeaveParty(c echo.Context) error {
+	playerId := c.Get("playerId").(string)
+
+	leaderId, err := h.Parties.Leave(c.Request().Context(), playerId)
+	if err != nil {
+		return h.partyError(c, err)
+	}
+
+	// the search of the leader must not seat the player who left
+	h.Cancel <- types.MatchCancel{PlayerId: playerId, Leader: leaderId, PartyLeft: true}
+
+	return c.NoContent(http.StatusNoContent)
+}
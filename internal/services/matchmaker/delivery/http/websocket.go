@@ -0,0 +1,41 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// keepAlive pings the client every ping interval until stop is called, and
+// makes reads fail once the client sent neither a frame nor a pong within
+// the pong wait. Readers call touch after every frame.
+func (h *Handler) keepAlive(ws *websocket.Conn) (touch func(), stop func()) {
+	cfg := h.Ctx.Config()
+	pongWait := cfg.GetPongWait()
+
+	touch = func() { ws.SetReadDeadline(time.Now().Add(pongWait)) }
+	touch()
+	ws.SetPongHandler(func(string) error {
+		touch()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.GetPingInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return touch, func() { close(done) }
+}
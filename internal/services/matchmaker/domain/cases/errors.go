@@ -5,7 +5,29 @@ import (
 	"fmt"
 )
 
-var ErrGroupNotFound = errors.New("matchmaker: group not found")
+var (
+	ErrGroupNotFound    = errors.New("matchmaker: group not found")
+	ErrInvalidTableSize = errors.New("matchmaker: invalid table size")
+	ErrUnknownMode      = errors.New("matchmaker: unknown mode")
+
+	ErrSubscriptionClosed = errors.New("matchmaker: subscription closed")
+
+	ErrAlreadyInParty = errors.New("matchmaker: already in a party")
+	ErrNotInParty     = errors.New("matchmaker: not in a party")
+	ErrNotPartyLeader = errors.New("matchmaker: not the party leader")
+	ErrNotInvited     = errors.New("matchmaker: not invited to the party")
+	ErrPartyFull      = errors.New("matchmaker: party is full")
+	ErrPartyTooLarge  = errors.New("matchmaker: party does not fit the table")
+
+	ErrLobbyNotFound        = errors.New("matchmaker: lobby not found")
+	ErrAlreadyInLobby       = errors.New("matchmaker: already in a lobby")
+	ErrNotInLobby           = errors.New("matchmaker: not in the lobby")
+	ErrNotLobbyHost         = errors.New("matchmaker: not the lobby host")
+	ErrLobbyFull            = errors.New("matchmaker: lobby is full")
+	ErrLobbyNotReady        = errors.New("matchmaker: not every player is ready")
+	ErrLobbyStarting        = errors.New("matchmaker: lobby game is starting")
+	ErrInvalidLobbySettings = errors.New("matchmaker: invalid lobby settings")
+)
 
 type ErrGroupTooSmall struct {
 	Gid int
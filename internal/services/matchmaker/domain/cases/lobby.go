@@ -0,0 +1,247 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LobbyMode is the mode of games started from a lobby. They are labelled
+// unranked, the label does not change results or ratings yet.
+const LobbyMode = "lobby"
+
+type LobbyUseCase struct {
+	ctx        domain.Context
+	gameClient game.GameClient
+}
+
+func NewLobbyUseCase(ctx domain.Context, gameClient game.GameClient) *LobbyUseCase {
+	return &LobbyUseCase{ctx: ctx, gameClient: gameClient}
+}
+
+// CreateLobby opens a lobby hosted by the player. Settings left out default
+// to the classic deck and the largest table it can deal to.
+func (uc *LobbyUseCase) CreateLobby(ctx context.Context, hostId string, settings models.LobbySettings) (models.Lobby, error) {
+	if settings.DeckSize == 0 {
+		settings.DeckSize = types.DefaultDeckSize
+	}
+	if settings.MaxPlayers == 0 {
+		settings.MaxPlayers = min(types.MaxTableSize, settings.DeckSize/types.HandSize)
+	}
+	if settings.MaxPlayers < types.MinTableSize || settings.MaxPlayers > types.MaxTableSize ||
+		!slices.Contains(types.DeckSizes, settings.DeckSize) ||
+		settings.MaxPlayers*types.HandSize > settings.DeckSize {
+		return models.Lobby{}, ErrInvalidLobbySettings
+	}
+
+	repo := uc.ctx.Connection().LobbyRepository()
+
+	current, err := repo.GetPlayerLobby(ctx, hostId)
+	if err != nil {
+		return models.Lobby{}, err
+	}
+	if current != "" {
+		return models.Lobby{}, ErrAlreadyInLobby
+	}
+
+	return repo.CreateLobby(ctx, models.Lobby{
+		HostId:   hostId,
+		Settings: settings,
+		Members:  []models.LobbyMember{{PlayerId: hostId}},
+	})
+}
+
+func (uc *LobbyUseCase) GetLobby(ctx context.Context, code string) (models.Lobby, error) {
+	lobby, err := uc.ctx.Connection().LobbyRepository().GetLobby(ctx, code)
+	if errors.Is(err, redis.Nil) {
+		return models.Lobby{}, ErrLobbyNotFound
+	}
+	return lobby, err
+}
+
+// ListLobbies returns the newest public lobbies.
+func (uc *LobbyUseCase) ListLobbies(ctx context.Context) ([]models.Lobby, error) {
+	return uc.ctx.Connection().LobbyRepository().ListPublicLobbies(ctx)
+}
+
+// Join adds the player to the lobby of the invite code.
+func (uc *LobbyUseCase) Join(ctx context.Context, playerId string, code string) (models.Lobby, error) {
+	repo := uc.ctx.Connection().LobbyRepository()
+
+	current, err := repo.GetPlayerLobby(ctx, playerId)
+	if err != nil {
+		return models.Lobby{}, err
+	}
+	if current != "" && current != code {
+		return models.Lobby{}, ErrAlreadyInLobby
+	}
+
+	return uc.update(ctx, code, func(lobby *models.Lobby) error {
+		if lobby.Member(playerId) >= 0 {
+			return nil
+		}
+		if len(lobby.Members) >= lobby.Settings.MaxPlayers {
+			return ErrLobbyFull
+		}
+		lobby.Members = append(lobby.Members, models.LobbyMember{PlayerId: playerId})
+		return nil
+	})
+}
+
+// SetReady marks the player of the lobby as ready or not.
+func (uc *LobbyUseCase) SetReady(ctx context.Context, playerId string, code string, ready bool) (models.Lobby, error) {
+	return uc.update(ctx, code, func(lobby *models.Lobby) error {
+		i := lobby.Member(playerId)
+		if i < 0 {
+			return ErrNotInLobby
+		}
+		lobby.Members[i].Ready = ready
+		return nil
+	})
+}
+
+// Leave takes the player out of the lobby. The member who joined next
+// becomes the host, and the lobby of the last member is closed.
+func (uc *LobbyUseCase) Leave(ctx context.Context, playerId string, code string) error {
+	repo := uc.ctx.Connection().LobbyRepository()
+
+	lobby, err := uc.update(ctx, code, func(lobby *models.Lobby) error {
+		i := lobby.Member(playerId)
+		if i < 0 {
+			return ErrNotInLobby
+		}
+		lobby.Members = slices.Delete(lobby.Members, i, i+1)
+		if lobby.HostId == playerId && len(lobby.Members) > 0 {
+			lobby.HostId = lobby.Members[0].PlayerId
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(lobby.Members) > 0 {
+		return nil
+	}
+
+	err = repo.DeleteLobby(ctx, code)
+	if err != nil {
+		return err
+	}
+	uc.publish(ctx, models.LobbyEvent{Type: models.LobbyClosed, Lobby: lobby})
+	return nil
+}
+
+// Start lets the host create the game once at least two players are in the
+// lobby and all of them are ready. The lobby is closed afterwards.
+func (uc *LobbyUseCase) Start(ctx context.Context, hostId string, code string) (string, error) {
+	repo := uc.ctx.Connection().LobbyRepository()
+
+	lobby, err := uc.update(ctx, code, func(lobby *models.Lobby) error {
+		if lobby.HostId != hostId {
+			return ErrNotLobbyHost
+		}
+		if len(lobby.Members) < types.MinTableSize {
+			return ErrLobbyNotReady
+		}
+		for _, member := range lobby.Members {
+			if !member.Ready {
+				return ErrLobbyNotReady
+			}
+		}
+		lobby.Starting = true
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	userIds := make([]string, len(lobby.Members))
+	for i, member := range lobby.Members {
+		userIds[i] = member.PlayerId
+	}
+
+	resp, err := uc.gameClient.CreateGame(ctx, &game.CreateGameRequest{
+		UserIds: userIds,
+		Settings: &game.GameSettings{
+			Mode:     LobbyMode,
+			DeckSize: int32(lobby.Settings.DeckSize),
+			Ranked:   false,
+		},
+	})
+	if err != nil {
+		// let the host try again
+		repo.UpdateLobby(ctx, code, func(lobby *models.Lobby) error {
+			lobby.Starting = false
+			return nil
+		})
+		if status.Code(err) == codes.InvalidArgument {
+			return "", ErrInvalidLobbySettings
+		}
+		return "", err
+	}
+
+	err = repo.DeleteLobby(ctx, code)
+	if err != nil {
+		return "", err
+	}
+
+	uc.publish(ctx, models.LobbyEvent{Type: models.LobbyStarted, Lobby: lobby, GameId: resp.GameId})
+	return resp.GameId, nil
+}
+
+// Watch subscribes a member to the events of the lobby and returns the
+// lobby as it was when the subscription started.
+func (uc *LobbyUseCase) Watch(ctx context.Context, playerId string, code string) (models.Lobby, <-chan models.LobbyEvent, func() error, error) {
+	repo := uc.ctx.Connection().LobbyRepository()
+
+	events, closeEvents, err := repo.Subscribe(ctx, code)
+	if err != nil {
+		return models.Lobby{}, nil, nil, err
+	}
+
+	lobby, err := uc.GetLobby(ctx, code)
+	if err == nil && lobby.Member(playerId) < 0 {
+		err = ErrNotInLobby
+	}
+	if err != nil {
+		closeEvents()
+		return models.Lobby{}, nil, nil, err
+	}
+
+	return lobby, events, closeEvents, nil
+}
+
+// update changes the lobby unless its game is being started and tells
+// everyone watching.
+func (uc *LobbyUseCase) update(ctx context.Context, code string, update func(lobby *models.Lobby) error) (models.Lobby, error) {
+	lobby, err := uc.ctx.Connection().LobbyRepository().UpdateLobby(ctx, code, func(lobby *models.Lobby) error {
+		if lobby.Starting {
+			return ErrLobbyStarting
+		}
+		return update(lobby)
+	})
+	if errors.Is(err, redis.Nil) {
+		return models.Lobby{}, ErrLobbyNotFound
+	} else if err != nil {
+		return models.Lobby{}, err
+	}
+
+	uc.publish(ctx, models.LobbyEvent{Type: models.LobbyUpdated, Lobby: lobby})
+	return lobby, nil
+}
+
+func (uc *LobbyUseCase) publish(ctx context.Context, event models.LobbyEvent) {
+	err := uc.ctx.Connection().LobbyRepository().Publish(ctx, event.Lobby.Code, event)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to publish lobby event", "code", event.Lobby.Code, "type", event.Type, "error", err.Error())
+	}
+}
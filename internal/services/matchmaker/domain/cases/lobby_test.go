@@ -0,0 +1,95 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/connection"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLobbyStartsUnrankedGame(t *testing.T) {
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	games := &testGames{games: make(map[string][]string), settings: make(map[string]*game.GameSettings)}
+	uc := NewLobbyUseCase(&testContext{cfg: testConfig{}, conn: connection.NewConnection(client)}, games)
+
+	if _, err := uc.CreateLobby(ctx, "host", models.LobbySettings{MaxPlayers: 6, DeckSize: 24}); !errors.Is(err, ErrInvalidLobbySettings) {
+		t.Errorf("Lobby of 6 players was dealt 24 cards: %v", err)
+	}
+
+	lobby, err := uc.CreateLobby(ctx, "host", models.LobbySettings{MaxPlayers: 2, DeckSize: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lobby.Code) != 6 {
+		t.Errorf("Expected a 6 character invite code, got %q", lobby.Code)
+	}
+
+	if _, err := uc.Join(ctx, "guest", lobby.Code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Join(ctx, "third", lobby.Code); !errors.Is(err, ErrLobbyFull) {
+		t.Errorf("Expected the lobby to be full, got %v", err)
+	}
+
+	_, events, closeEvents, err := uc.Watch(ctx, "guest", lobby.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeEvents()
+
+	if _, err := uc.SetReady(ctx, "host", lobby.Code, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Start(ctx, "host", lobby.Code); !errors.Is(err, ErrLobbyNotReady) {
+		t.Errorf("Lobby started before everyone was ready: %v", err)
+	}
+	if _, err := uc.SetReady(ctx, "guest", lobby.Code, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Start(ctx, "guest", lobby.Code); !errors.Is(err, ErrNotLobbyHost) {
+		t.Errorf("Guest could start the game: %v", err)
+	}
+
+	gameId, err := uc.Start(ctx, "host", lobby.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := games.gameSettings(gameId)
+	if settings.GetRanked() {
+		t.Error("The lobby game was created ranked")
+	}
+	if settings.GetDeckSize() != 24 || len(games.players(gameId)) != 2 {
+		t.Errorf("Unexpected game %v with %v", settings, games.players(gameId))
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type != models.LobbyStarted {
+				continue
+			}
+			if event.GameId != gameId {
+				t.Errorf("Watcher was sent to %s instead of %s", event.GameId, gameId)
+			}
+			if _, err := uc.GetLobby(ctx, lobby.Code); !errors.Is(err, ErrLobbyNotFound) {
+				t.Errorf("Lobby is still open after the start: %v", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Watcher did not see the game start")
+		}
+	}
+}
@@ -3,106 +3,390 @@ package cases
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
-const increaceRangeAfter = 5
-const increaceRangeBy = 100
-const groupSize = 2
-
+// MatchmakerUseCase runs on every replica of the matchmaker. Each replica
+// stores the searches of its WebSocket clients in redis and delivers the
+// responses published for them, while the matching loop runs only on the
+// replica elected leader.
 type MatchmakerUseCase struct {
 	ctx        domain.Context
 	queueChan  <-chan types.MatchChan
 	cancelChan <-chan types.MatchCancel
-	queue      map[string]types.MatchChan
+	acceptChan <-chan types.MatchAccept
+	modes      map[string]types.Mode
 	gameClient game.GameClient
+
+	// pod identifies the replica in the shared searches.
+	pod string
+	// clients are shared by serve and deliver, guarded by mu.
+	mu      sync.Mutex
+	clients map[string]chan types.MatchResponse
+
+	// searches, checks and checking belong to the matching loop.
+	searches map[string]types.MatchChan
+	checks   []*readyCheck
+	checking map[string]*readyCheck
+}
+
+// ModeByName looks up a configured mode, the default one when name is empty.
+func ModeByName(cfg infra.Config, name string) (types.Mode, error) {
+	if name == "" {
+		name = cfg.GetDefaultMode()
+	}
+	for _, mode := range cfg.GetModes() {
+		if mode.Name == name {
+			return mode, nil
+		}
+	}
+	return types.Mode{}, ErrUnknownMode
 }
 
 func NewMatchmakerUseCase(
 	ctx domain.Context,
 	queueChan <-chan types.MatchChan,
 	cancelChan <-chan types.MatchCancel,
+	acceptChan <-chan types.MatchAccept,
 	gameGRPCClient game.GameClient,
 ) *MatchmakerUseCase {
-	queue := make(map[string]types.MatchChan)
+	modes := make(map[string]types.Mode)
+	for _, mode := range ctx.Config().GetModes() {
+		modes[mode.Name] = mode
+	}
 	return &MatchmakerUseCase{
 		ctx:        ctx,
 		queueChan:  queueChan,
 		cancelChan: cancelChan,
-		queue:      queue,
+		acceptChan: acceptChan,
+		modes:      modes,
 		gameClient: gameGRPCClient,
+		pod:        uuid.NewString(),
+		clients:    make(map[string]chan types.MatchResponse),
+		searches:   make(map[string]types.MatchChan),
+		checking:   make(map[string]*readyCheck),
 	}
 }
 
 func (uc *MatchmakerUseCase) Start(ctx context.Context) error {
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case matchChan := <-uc.queueChan:
-				uc.queue[matchChan.PlayerId] = matchChan
-			case cancelRequest := <-uc.cancelChan:
-				err := uc.ctx.Connection().MatchmakerRepository().RemovePlayer(ctx, cancelRequest.PlayerId)
+	searches := uc.ctx.Connection().SearchRepository()
+	leader := uc.ctx.Connection().LeaderRepository()
+	ttl := uc.ctx.Config().GetLeaderTTL()
+
+	notifications, closeNotifications, err := searches.Subscribe(ctx, uc.pod)
+	if err != nil {
+		return err
+	}
+	defer closeNotifications()
+
+	answers, closeAnswers, err := searches.SubscribeAnswers(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAnswers()
+
+	err = searches.Heartbeat(ctx, uc.pod, ttl)
+	if err != nil {
+		return err
+	}
+
+	go uc.serve(ctx)
+	go uc.deliver(ctx, notifications)
+
+	leading := false
+	ticker := time.NewTicker(1 * time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			if leading {
+				err := leader.Release(context.WithoutCancel(ctx), uc.pod)
 				if err != nil {
-					panic(err)
+					uc.ctx.Logger().ErrorContext(ctx, "Failed to release leadership", "pod", uc.pod, "error", err.Error())
 				}
-				delete(uc.queue, cancelRequest.PlayerId)
+			}
+			return nil
+		case answer, ok := <-answers:
+			if !ok {
+				return ErrSubscriptionClosed
+			}
+			uc.answer(answer)
+		case <-ticker.C:
+			err := searches.Heartbeat(ctx, uc.pod, ttl)
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to send heartbeat", "pod", uc.pod, "error", err.Error())
+			}
+
+			elected, err := leader.Acquire(ctx, uc.pod, ttl)
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to acquire leadership", "pod", uc.pod, "error", err.Error())
+				elected = false
+			}
+			if elected != leading {
+				uc.ctx.Logger().InfoContext(ctx, "Matchmaker leadership changed", "pod", uc.pod, "leader", elected)
+				leading = elected
+			}
+			if !leading {
+				uc.dropReadyChecks()
+				continue
+			}
+
+			err = uc.matchmake(ctx)
+			if err != nil {
+				return err
+			}
+			err = uc.resolveReadyChecks(ctx)
+			if err != nil {
+				return err
 			}
 		}
-	}()
+	}
+}
+
+// serve takes the requests of the WebSocket clients of the replica.
+func (uc *MatchmakerUseCase) serve(ctx context.Context) {
+	searches := uc.ctx.Connection().SearchRepository()
 
-	ticker := time.NewTicker(1 * time.Second)
 	for {
 		select {
 		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-			if len(uc.queue) == 0 {
+			return
+		case matchChan := <-uc.queueChan:
+			if matchChan.Leader == "" {
+				if _, ok := uc.modes[matchChan.Mode]; !ok {
+					uc.send(ctx, matchChan.PlayerId, matchChan.ReturnChan, types.MatchResponse{Status: types.MatchError, Error: ErrUnknownMode})
+					uc.respond(ctx, matchChan, types.MatchResponse{Status: types.MatchCancelled})
+					continue
+				}
+			}
+
+			uc.mu.Lock()
+			uc.clients[matchChan.PlayerId] = matchChan.ReturnChan
+			uc.mu.Unlock()
+
+			err := searches.SetOwner(ctx, matchChan.PlayerId, uc.pod)
+			// party members only follow the search of the leader
+			if err == nil && matchChan.Leader == "" {
+				matchChan.Pod = uc.pod
+				err = searches.SaveSearch(ctx, matchChan)
+			}
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to save search", "player_id", matchChan.PlayerId, "error", err.Error())
+				uc.leave(ctx, matchChan.PlayerId)
+				uc.send(ctx, matchChan.PlayerId, matchChan.ReturnChan, types.MatchResponse{Status: types.MatchError, Error: err})
+				uc.respond(ctx, matchChan, types.MatchResponse{Status: types.MatchCancelled})
+			}
+		case accept := <-uc.acceptChan:
+			err := searches.PublishAnswer(ctx, accept)
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to publish answer", "player_id", accept.PlayerId, "error", err.Error())
+			}
+		case cancelRequest := <-uc.cancelChan:
+			if !cancelRequest.PartyLeft {
+				uc.leave(ctx, cancelRequest.PlayerId)
+			}
+
+			// leaving during a ready-check declines the match
+			err := searches.PublishAnswer(ctx, types.MatchAccept{PlayerId: cancelRequest.PlayerId})
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to publish answer", "player_id", cancelRequest.PlayerId, "error", err.Error())
+			}
+
+			searchId := cancelRequest.PlayerId
+			if cancelRequest.Leader != "" {
+				searchId = cancelRequest.Leader
+			}
+			uc.cancelSearch(ctx, searchId)
+		}
+	}
+}
+
+// cancelSearch ends the search of the player or party leader and tells the
+// members of the party who are still waiting.
+func (uc *MatchmakerUseCase) cancelSearch(ctx context.Context, playerId string) {
+	searches := uc.ctx.Connection().SearchRepository()
+
+	search, err := searches.GetSearch(ctx, playerId)
+	if errors.Is(err, redis.Nil) {
+		return
+	}
+	if err == nil {
+		err = searches.DeleteSearch(ctx, playerId)
+	}
+	if err == nil {
+		err = uc.ctx.Connection().MatchmakerRepository(search.Mode).RemovePlayer(ctx, playerId)
+	}
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to remove player", "player_id", playerId, "error", err.Error())
+		return
+	}
+
+	uc.respond(ctx, search, types.MatchResponse{Status: types.MatchCancelled})
+}
+
+// deliver passes the notifications published for the replica to its
+// WebSocket clients.
+func (uc *MatchmakerUseCase) deliver(ctx context.Context, notifications <-chan types.Notification) {
+	for notification := range notifications {
+		final := notification.Response.Status == types.MatchCreated ||
+			notification.Response.Status == types.MatchDeclined ||
+			notification.Response.Status == types.MatchCancelled
+
+		for _, playerId := range notification.PlayerIds {
+			uc.mu.Lock()
+			returnChan, ok := uc.clients[playerId]
+			uc.mu.Unlock()
+			if !ok {
 				continue
 			}
-			err := uc.matchmake(ctx)
+
+			uc.send(ctx, playerId, returnChan, notification.Response)
+			if final {
+				uc.leave(ctx, playerId)
+			}
+		}
+	}
+}
+
+// send never waits for the client: the channels of the clients are
+// buffered, and a client that fell that far behind misses pending updates.
+// A found match or a final status takes the place of the oldest queued
+// response instead, which it supersedes.
+func (uc *MatchmakerUseCase) send(ctx context.Context, playerId string, returnChan chan types.MatchResponse, response types.MatchResponse) {
+	for {
+		select {
+		case returnChan <- response:
+			return
+		default:
+		}
+
+		if !mustDeliver(response.Status) {
+			uc.ctx.Logger().WarnContext(ctx, "Dropped response for a slow client", "player_id", playerId, "status", response.Status.String())
+			return
+		}
+
+		select {
+		case dropped := <-returnChan:
+			uc.ctx.Logger().WarnContext(ctx, "Dropped response for a slow client", "player_id", playerId, "status", dropped.Status.String())
+		default:
+		}
+	}
+}
+
+// mustDeliver tells the statuses a client cannot do without: it waits for
+// them to answer a match or to end the search.
+func mustDeliver(status types.ItemStatus) bool {
+	switch status {
+	case types.MatchFound, types.MatchCreated, types.MatchDeclined, types.MatchCancelled:
+		return true
+	}
+	return false
+}
+
+// leave forgets the WebSocket client of the player.
+func (uc *MatchmakerUseCase) leave(ctx context.Context, playerId string) {
+	uc.mu.Lock()
+	delete(uc.clients, playerId)
+	uc.mu.Unlock()
+
+	err := uc.ctx.Connection().SearchRepository().DeleteOwner(ctx, playerId)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to delete owner", "player_id", playerId, "error", err.Error())
+	}
+}
+
+// loadSearches reads the searches of every replica and drops those of
+// replicas that stopped.
+func (uc *MatchmakerUseCase) loadSearches(ctx context.Context) error {
+	searches := uc.ctx.Connection().SearchRepository()
+
+	stored, err := searches.ListSearches(ctx)
+	if err != nil {
+		return err
+	}
+
+	alive := make(map[string]bool)
+	uc.searches = make(map[string]types.MatchChan, len(stored))
+	for _, search := range stored {
+		podAlive, ok := alive[search.Pod]
+		if !ok {
+			podAlive, err = searches.IsAlive(ctx, search.Pod)
 			if err != nil {
 				return err
 			}
+			alive[search.Pod] = podAlive
+		}
+
+		if !podAlive {
+			err := searches.DeleteSearch(ctx, search.PlayerId)
+			if err == nil {
+				err = uc.ctx.Connection().MatchmakerRepository(search.Mode).RemovePlayer(ctx, search.PlayerId)
+			}
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to drop search", "player_id", search.PlayerId, "error", err.Error())
+			}
+			// members of the party may wait on replicas that are still up
+			uc.respond(ctx, search, types.MatchResponse{Status: types.MatchCancelled})
+			continue
+		}
+
+		// replicas may be configured with different modes while rolling out
+		if _, ok := uc.modes[search.Mode]; !ok {
+			continue
 		}
+		uc.searches[search.PlayerId] = search
 	}
+	return nil
 }
 
 func (uc *MatchmakerUseCase) matchmake(ctx context.Context) error {
-	repo := uc.ctx.Connection().MatchmakerRepository()
+	err := uc.loadSearches(ctx)
+	if err != nil {
+		return err
+	}
+
+	for playerId, player := range uc.searches {
+		if _, ok := uc.checking[playerId]; ok {
+			continue
+		}
 
-	for playerId, player := range uc.queue {
+		repo := uc.ctx.Connection().MatchmakerRepository(player.Mode)
 		storedPlayer, err := repo.GetPlayer(ctx, playerId)
 		if err != nil {
-			storedPlayer, _ = repo.AddPlayer(ctx, playerId, player.Rating)
+			storedPlayer, err = repo.AddPlayer(ctx, playerId, player.Rating, player.MaxSize, player.Party)
+			// the player left since the searches were loaded
+			if errors.Is(err, repositories.ErrConflict) {
+				continue
+			} else if err != nil {
+				return err
+			}
 		}
 
 		switch storedPlayer.Status {
 		case models.StatusSearch:
-			player.ReturnChan <- types.MatchResponse{
+			uc.respond(ctx, player, types.MatchResponse{
 				Status: types.MatchPending,
-			}
+			})
 
-			err := uc.handleSearch(ctx, player)
+			err := uc.handleSearch(ctx, player, storedPlayer.Size)
 			if errors.Is(err, ErrGroupNotFound) {
 				continue
 			} else if err != nil {
 				return err
 			}
 		case models.StatusMoved:
-			player.ReturnChan <- types.MatchResponse{
-				Status: types.MatchFoundGroup,
-			}
-
-			err := uc.handleMoved(ctx, storedPlayer)
-			var gidError ErrGroupTooSmall
+			err := uc.handleMoved(ctx, player, storedPlayer)
+			var gidError *ErrGroupTooSmall
 			if errors.As(err, &gidError) {
 				continue
 			} else if err != nil {
@@ -113,91 +397,202 @@ func (uc *MatchmakerUseCase) matchmake(ctx context.Context) error {
 	return nil
 }
 
-func (uc *MatchmakerUseCase) handleSearch(ctx context.Context, player types.MatchChan) error {
-	repo := uc.ctx.Connection().MatchmakerRepository()
+// respond notifies the player and the members of its party who follow the
+// search, wherever their WebSockets are.
+func (uc *MatchmakerUseCase) respond(ctx context.Context, player types.MatchChan, response types.MatchResponse) {
+	playerIds := append([]string{player.PlayerId}, player.Party...)
+	err := uc.ctx.Connection().SearchRepository().Notify(ctx, playerIds, response)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to notify players", "player_id", player.PlayerId, "error", err.Error())
+	}
+}
+
+// ValidateTableSize checks a requested range of table sizes.
+func ValidateTableSize(minSize, maxSize int) error {
+	if minSize < types.MinTableSize || maxSize > types.MaxTableSize || minSize > maxSize {
+		return ErrInvalidTableSize
+	}
+	return nil
+}
 
-	scoreRange := int(max((time.Now().Unix()-player.SentTime.Unix())/increaceRangeAfter, 1) * increaceRangeBy)
+// handleSearch puts the player into a group with enough free seats for its
+// party or forms a new group from the players searching for tables of the
+// same size.
+func (uc *MatchmakerUseCase) handleSearch(ctx context.Context, player types.MatchChan, size int) error {
+	repo := uc.ctx.Connection().MatchmakerRepository(player.Mode)
+	mode := uc.modes[player.Mode]
+
+	scoreRange := int(max(time.Since(player.SentTime)/mode.RangeAfter, 1)) * mode.RangeBy
 	low := player.Rating - scoreRange
 	high := player.Rating + scoreRange
 
-	count, err := repo.CountGroups(ctx)
+	seats := len(player.Party) + 1
+
+	count, err := repo.CountGroups(ctx, size)
 	if err != nil {
 		return err
 	}
 
 	if count > 0 {
-		groups, err := repo.ListGroupsRange(ctx, low, high)
+		groups, err := repo.ListGroupsRange(ctx, size, low, high)
 		if err != nil {
 			return err
 		}
 
-		if len(groups) > 0 {
-			groupId, err := repo.ParseGroupId(groups[0].Member.(string))
+		for _, group := range groups {
+			groupId, err := repo.ParseGroupId(group.Member.(string))
 			if err != nil {
 				return err
 			}
 
-			repo.AddToGroup(ctx, groupId, redis.Z{Score: float64(player.Rating), Member: player.PlayerId})
-			return nil
-		}
-	} else {
-		players, err := repo.ListPlayersRange(ctx, low, high)
-		if err != nil {
+			groupLen, err := repo.GetGroupLen(ctx, groupId)
+			if err != nil {
+				return err
+			}
+			if groupLen+seats > size {
+				continue
+			}
+
+			err = repo.AddToGroup(ctx, size, groupId, redis.Z{Score: float64(player.Rating), Member: player.PlayerId})
+			if errors.Is(err, repositories.ErrConflict) {
+				continue
+			}
 			return err
 		}
+	}
 
-		if len(players) <= 1 {
-			return ErrGroupNotFound
-		}
+	players, err := repo.ListPlayersRange(ctx, size, low, high)
+	if err != nil {
+		return err
+	}
+
+	group, groupSeats, err := uc.pickPlayers(ctx, player.Mode, players, size)
+	if err != nil {
+		return err
+	}
+	if len(group) < 2 || groupSeats < types.MinTableSize {
+		return ErrGroupNotFound
+	}
+
+	err = repo.AddGroup(ctx, size, group)
+	if errors.Is(err, repositories.ErrConflict) {
+		return ErrGroupNotFound
+	}
+	return err
+}
+
+// pickPlayers takes players in order as long as their parties fit the
+// table.
+func (uc *MatchmakerUseCase) pickPlayers(ctx context.Context, mode string, players []redis.Z, size int) ([]redis.Z, int, error) {
+	repo := uc.ctx.Connection().MatchmakerRepository(mode)
 
-		err = repo.AddGroup(ctx, players[:min(groupSize, len(players))])
+	var group []redis.Z
+	seats := 0
+	for _, candidate := range players {
+		candidateSeats, err := repo.GetSeats(ctx, candidate.Member.(string))
 		if err != nil {
-			return err
+			return nil, 0, err
+		}
+		if seats+candidateSeats > size {
+			continue
+		}
+
+		group = append(group, candidate)
+		seats += candidateSeats
+		if seats == size {
+			break
 		}
 	}
-	return nil
+	return group, seats, nil
 }
 
+// handleMoved takes the group of the player out of the queue once the group
+// is full, or once it has waited long enough and is large enough for every
+// member, and asks its players to accept the match.
 func (uc *MatchmakerUseCase) handleMoved(
 	ctx context.Context,
+	player types.MatchChan,
 	storedPlayer models.RedisPlayer,
 ) error {
-	repo := uc.ctx.Connection().MatchmakerRepository()
+	repo := uc.ctx.Connection().MatchmakerRepository(player.Mode)
 
-	len, err := repo.GetGroupLen(ctx, storedPlayer.Gid)
+	entries, err := repo.GetGrouppedPlayers(ctx, storedPlayer.Gid, storedPlayer.Size)
 	if err != nil {
 		return err
 	}
 
-	if len < groupSize {
-		return NewGroupTooSmall(storedPlayer.Gid)
+	// the group is gone, e.g. after a restart during a ready-check
+	if !slices.Contains(entries, player.PlayerId) {
+		return repo.SetPlayerStatus(ctx, player.PlayerId, models.StatusEmpty)
 	}
 
-	grouppedPlayers, err := repo.GetGrouppedPlayers(ctx, storedPlayer.Gid, groupSize)
-	if err != nil {
-		return err
+	// parties are stored as their leader, the game seats every member
+	grouppedPlayers := make([]string, 0, len(entries))
+	parties := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		party, err := repo.GetPartyMembers(ctx, entry)
+		if err != nil {
+			return err
+		}
+		parties[entry] = party
+		grouppedPlayers = append(grouppedPlayers, entry)
+		grouppedPlayers = append(grouppedPlayers, party...)
 	}
 
-	err = repo.RemoveGroup(ctx, storedPlayer.Gid)
-	if err != nil {
-		return err
+	uc.respond(ctx, player, types.MatchResponse{
+		Status:    types.MatchFoundGroup,
+		GroupSize: len(grouppedPlayers),
+	})
+
+	if len(grouppedPlayers) < uc.requiredGroupLen(storedPlayer.Size, entries) {
+		return NewGroupTooSmall(storedPlayer.Gid)
 	}
 
-	gameId, err := uc.gameClient.CreateGame(ctx, &game.CreateGameRequest{UserIds: grouppedPlayers})
+	claimed, err := repo.RemoveGroup(ctx, storedPlayer.Size, storedPlayer.Gid)
 	if err != nil {
 		return err
 	}
+	// a replica that was leader before this one already took the group
+	if !claimed {
+		return nil
+	}
 
-	response := types.MatchResponse{
-		Status: types.MatchCreated,
-		RoomId: gameId.GameId,
+	check := &readyCheck{
+		mode:     uc.modes[player.Mode],
+		entries:  entries,
+		parties:  parties,
+		players:  grouppedPlayers,
+		accepted: make(map[string]bool),
 	}
+	if uc.ctx.Config().GetReadyCheckTimeout() <= 0 {
+		// without the leadership the group is gone, the next leader queues
+		// its players again
+		uc.createGame(ctx, check)
+	} else {
+		uc.startReadyCheck(ctx, check)
+	}
+	return nil
+}
 
-	for _, grouppedPlayer := range grouppedPlayers {
-		repo.SetPlayerStatus(ctx, grouppedPlayer, models.StatusEmpty)
+// requiredGroupLen is the size of the table until the longest waiting member
+// has waited for SmallerTableAfter. From then on the group may start with as
+// few players as its most demanding member accepts.
+func (uc *MatchmakerUseCase) requiredGroupLen(size int, members []string) int {
+	var firstSent time.Time
+	required := types.MinTableSize
+	for _, member := range members {
+		queued, ok := uc.searches[member]
+		if !ok {
+			continue
+		}
+		if firstSent.IsZero() || queued.SentTime.Before(firstSent) {
+			firstSent = queued.SentTime
+		}
+		required = max(required, queued.MinSize)
+	}
 
-		uc.queue[grouppedPlayer].ReturnChan <- response
-		delete(uc.queue, grouppedPlayer)
+	if firstSent.IsZero() || time.Since(firstSent) < uc.ctx.Config().GetSmallerTableAfter() {
+		return size
 	}
-	return nil
+	return min(required, size)
 }
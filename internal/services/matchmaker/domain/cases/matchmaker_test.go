@@ -0,0 +1,568 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/connection"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/infra"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+type testConfig struct {
+	infra.Config
+	smallerTableAfter time.Duration
+	readyCheckTimeout time.Duration
+}
+
+func (c testConfig) GetSmallerTableAfter() time.Duration { return c.smallerTableAfter }
+func (c testConfig) GetDefaultMode() string              { return "table" }
+func (c testConfig) GetReadyCheckTimeout() time.Duration { return c.readyCheckTimeout }
+func (c testConfig) GetDeclinePenalty() time.Duration    { return time.Minute }
+func (c testConfig) GetPartyMaxSize() int                { return 3 }
+func (c testConfig) GetLeaderTTL() time.Duration         { return 5 * time.Second }
+func (c testConfig) GetModes() []types.Mode {
+	return []types.Mode{
+		{Name: "table", MinSize: 2, MaxSize: 6, DeckSize: 36, Ranked: true, RangeAfter: time.Second, RangeBy: 100},
+		{Name: "quick", MinSize: 2, MaxSize: 2, DeckSize: 24, RangeAfter: time.Second, RangeBy: 100},
+	}
+}
+
+type testContext struct {
+	cfg  infra.Config
+	conn domain.Connection
+}
+
+func (c *testContext) Make() domain.Context          { return c }
+func (c *testContext) Connection() domain.Connection { return c.conn }
+func (c *testContext) Config() infra.Config          { return c.cfg }
+func (c *testContext) Logger() *slog.Logger          { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+// testGames records the players and settings of every created game.
+type testGames struct {
+	mu       sync.Mutex
+	games    map[string][]string
+	settings map[string]*game.GameSettings
+	// failures is the number of games to fail before the next one is
+	// created.
+	failures int
+}
+
+func (g *testGames) CreateGame(ctx context.Context, in *game.CreateGameRequest, opts ...grpc.CallOption) (*game.CreateGameResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.failures > 0 {
+		g.failures--
+		return nil, errors.New("game service unavailable")
+	}
+
+	gameId := uuid.NewString()
+	g.games[gameId] = in.UserIds
+	g.settings[gameId] = in.Settings
+	return &game.CreateGameResponse{GameId: gameId}, nil
+}
+
+func (g *testGames) players(gameId string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.games[gameId]
+}
+
+func (g *testGames) gameSettings(gameId string) *game.GameSettings {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.settings[gameId]
+}
+
+// testPod holds the channels the handlers of a matchmaker replica use.
+type testPod struct {
+	queue  chan<- types.MatchChan
+	cancel chan<- types.MatchCancel
+	accept chan<- types.MatchAccept
+}
+
+func startMatchmaker(t *testing.T, cfg testConfig) (testPod, *testGames) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	games := &testGames{games: make(map[string][]string), settings: make(map[string]*game.GameSettings)}
+	return startPod(t, cfg, client, games), games
+}
+
+// startPod runs a matchmaker replica on the shared redis.
+func startPod(t *testing.T, cfg testConfig, client *redis.Client, games *testGames) testPod {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	queue := make(chan types.MatchChan)
+	cancelChan := make(chan types.MatchCancel)
+	accept := make(chan types.MatchAccept)
+	uc := NewMatchmakerUseCase(
+		&testContext{cfg: cfg, conn: connection.NewConnection(client)},
+		queue,
+		cancelChan,
+		accept,
+		games,
+	)
+	go uc.Start(ctx)
+
+	return testPod{queue: queue, cancel: cancelChan, accept: accept}
+}
+
+// searchOption changes the search queued by watch.
+type searchOption func(*types.MatchChan)
+
+// inMode searches in mode for tables of minSize to maxSize players.
+func inMode(mode string, minSize, maxSize int) searchOption {
+	return func(request *types.MatchChan) {
+		request.Mode, request.MinSize, request.MaxSize = mode, minSize, maxSize
+	}
+}
+
+// withParty searches for the leader together with the other members.
+func withParty(members ...string) searchOption {
+	return func(request *types.MatchChan) { request.Party = members }
+}
+
+// following makes the player a party member following the search of leader.
+func following(leader string) searchOption {
+	return func(request *types.MatchChan) {
+		*request = types.MatchChan{PlayerId: request.PlayerId, Leader: leader}
+	}
+}
+
+// watch queues the player, for a heads-up table unless the options say
+// otherwise, and collects every response.
+func watch(queue chan<- types.MatchChan, playerId string, options ...searchOption) <-chan types.MatchResponse {
+	responses := make(chan types.MatchResponse, 64)
+	request := types.MatchChan{
+		PlayerId: playerId,
+		Mode:     "table",
+		Rating:   1000,
+		MinSize:  2,
+		MaxSize:  2,
+		SentTime: time.Now(),
+	}
+	for _, option := range options {
+		option(&request)
+	}
+	request.ReturnChan = responses
+	queue <- request
+
+	collected := make(chan types.MatchResponse, 64)
+	go func() {
+		for response := range responses {
+			collected <- response
+		}
+	}()
+	return collected
+}
+
+// search queues the player and returns the id of the game it was matched
+// into.
+func search(queue chan<- types.MatchChan, playerId string, options ...searchOption) <-chan string {
+	responses := watch(queue, playerId, options...)
+
+	gameId := make(chan string, 1)
+	go func() {
+		for response := range responses {
+			if response.Status == types.MatchCreated {
+				gameId <- response.RoomId
+				return
+			}
+		}
+	}()
+	return gameId
+}
+
+func TestMatchmakerFillsTable(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+	queue := pod.queue
+
+	// a heads-up player never joins the table of three
+	headsUp := search(queue, "heads-up", inMode("table", 2, 2))
+
+	var matches []<-chan string
+	for _, playerId := range []string{"p1", "p2", "p3"} {
+		matches = append(matches, search(queue, playerId, inMode("table", 3, 3)))
+	}
+
+	var gameId string
+	for _, match := range matches {
+		select {
+		case gameId = <-match:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a match")
+		}
+	}
+
+	if players := games.players(gameId); len(players) != 3 {
+		t.Errorf("Expected a table of 3, got %v", players)
+	}
+
+	select {
+	case id := <-headsUp:
+		t.Errorf("Heads-up player was matched into %s", id)
+	default:
+	}
+}
+
+func TestMatchmakerAcceptsSmallerTable(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Second})
+	queue := pod.queue
+
+	matches := []<-chan string{
+		search(queue, "p1", inMode("table", 2, 4)),
+		search(queue, "p2", inMode("table", 2, 4)),
+	}
+
+	var gameId string
+	for _, match := range matches {
+		select {
+		case gameId = <-match:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a smaller table")
+		}
+	}
+
+	if players := games.players(gameId); len(players) != 2 {
+		t.Errorf("Expected a table of 2, got %v", players)
+	}
+}
+
+func TestMatchmakerKeepsModesApart(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+	queue := pod.queue
+
+	table := search(queue, "p1", inMode("table", 2, 2))
+	matches := []<-chan string{
+		search(queue, "p2", inMode("quick", 2, 2)),
+		search(queue, "p3", inMode("quick", 2, 2)),
+	}
+
+	var gameId string
+	for _, match := range matches {
+		select {
+		case gameId = <-match:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a match")
+		}
+	}
+
+	settings := games.gameSettings(gameId)
+	if settings.GetMode() != "quick" || settings.GetDeckSize() != 24 || settings.GetRanked() {
+		t.Errorf("Game was created with the wrong settings: %v", settings)
+	}
+
+	select {
+	case id := <-table:
+		t.Errorf("Player of another mode was matched into %s", id)
+	default:
+	}
+}
+
+func TestMatchmakerAcrossPods(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := testConfig{smallerTableAfter: time.Hour, readyCheckTimeout: 10 * time.Second}
+	games := &testGames{games: make(map[string][]string), settings: make(map[string]*game.GameSettings)}
+	podA := startPod(t, cfg, client, games)
+	podB := startPod(t, cfg, client, games)
+
+	// only one of the pods runs the matching loop, both players are matched
+	p1 := watch(podA.queue, "p1")
+	p2 := watch(podB.queue, "p2")
+	waitFor(t, p1, types.MatchFound)
+	waitFor(t, p2, types.MatchFound)
+
+	// each pod passes the answer of its own player
+	podA.accept <- types.MatchAccept{PlayerId: "p1", Accept: true}
+	podB.accept <- types.MatchAccept{PlayerId: "p2", Accept: true}
+	created := waitFor(t, p1, types.MatchCreated)
+	if other := waitFor(t, p2, types.MatchCreated); other.RoomId != created.RoomId {
+		t.Errorf("Players were sent to %s and %s", created.RoomId, other.RoomId)
+	}
+
+	if players := games.players(created.RoomId); len(players) != 2 {
+		t.Errorf("Expected a table of 2, got %v", players)
+	}
+}
+
+func TestMatchmakerSeatsPartyTogether(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+	queue := pod.queue
+
+	follower := watch(queue, "member", following("leader"))
+
+	matches := []<-chan string{
+		search(queue, "leader", withParty("member"), inMode("table", 3, 3)),
+		search(queue, "solo", inMode("table", 3, 3)),
+	}
+
+	var gameId string
+	for _, match := range matches {
+		select {
+		case gameId = <-match:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a match")
+		}
+	}
+
+	if players := games.players(gameId); len(players) != 3 {
+		t.Errorf("Expected the party and the solo player at one table, got %v", players)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case response := <-follower:
+			if response.Status != types.MatchCreated {
+				continue
+			}
+			if response.RoomId != gameId {
+				t.Errorf("Party member was sent to %s instead of %s", response.RoomId, gameId)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Party member did not receive the created game")
+		}
+	}
+}
+
+func TestMatchmakerEndsPartySearch(t *testing.T) {
+	pod, _ := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+
+	// nobody else searches, so the party keeps waiting until a member leaves
+	member := watch(pod.queue, "member", following("leader"))
+	other := watch(pod.queue, "other", following("leader"))
+	leader := watch(pod.queue, "leader", withParty("member", "other"), inMode("table", 6, 6))
+	waitFor(t, leader, types.MatchPending)
+
+	pod.cancel <- types.MatchCancel{PlayerId: "member", Leader: "leader"}
+	waitFor(t, leader, types.MatchCancelled)
+	waitFor(t, other, types.MatchCancelled)
+
+	select {
+	case response := <-member:
+		if response.Status == types.MatchCancelled {
+			t.Error("The member who left was told about its own cancel")
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the next search of the leader starts afresh
+	solo := watch(pod.queue, "solo")
+	leader = watch(pod.queue, "leader")
+	waitFor(t, leader, types.MatchCreated)
+	waitFor(t, solo, types.MatchCreated)
+}
+
+func TestMatchmakerEndsSearchOnPartyLeave(t *testing.T) {
+	pod, _ := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+
+	member := watch(pod.queue, "member", following("leader"))
+	leader := watch(pod.queue, "leader", withParty("member"), inMode("table", 6, 6))
+	waitFor(t, leader, types.MatchPending)
+
+	// the member left the party over HTTP and still watches the search
+	pod.cancel <- types.MatchCancel{PlayerId: "member", Leader: "leader", PartyLeft: true}
+	waitFor(t, leader, types.MatchCancelled)
+	waitFor(t, member, types.MatchCancelled)
+
+	// the member searches on its own now
+	solo := watch(pod.queue, "solo")
+	member = watch(pod.queue, "member")
+	waitFor(t, member, types.MatchCreated)
+	waitFor(t, solo, types.MatchCreated)
+}
+
+func TestMatchmakerReadyCheck(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour, readyCheckTimeout: 10 * time.Second})
+	queue, accept := pod.queue, pod.accept
+
+	p1 := watch(queue, "p1")
+	p2 := watch(queue, "p2")
+
+	found := waitFor(t, p1, types.MatchFound)
+	if found.Deadline.IsZero() {
+		t.Error("match_found was sent without a deadline")
+	}
+	waitFor(t, p2, types.MatchFound)
+
+	accept <- types.MatchAccept{PlayerId: "p1", Accept: true}
+	accept <- types.MatchAccept{PlayerId: "p2", Accept: false}
+	waitFor(t, p2, types.MatchDeclined)
+
+	// p1 is queued again and matched with the next player
+	p3 := watch(queue, "p3")
+	waitFor(t, p1, types.MatchFound)
+	waitFor(t, p3, types.MatchFound)
+
+	accept <- types.MatchAccept{PlayerId: "p1", Accept: true}
+	accept <- types.MatchAccept{PlayerId: "p3", Accept: true}
+	created := waitFor(t, p1, types.MatchCreated)
+	waitFor(t, p3, types.MatchCreated)
+
+	if players := games.players(created.RoomId); len(players) != 2 || slices.Contains(players, "p2") {
+		t.Errorf("Expected p1 and p3 in the game, got %v", players)
+	}
+}
+
+func TestMatchmakerRequeuesFailedGame(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+	games.failures = 1
+
+	p1 := watch(pod.queue, "p1")
+	p2 := watch(pod.queue, "p2")
+
+	failed := waitFor(t, p1, types.MatchError)
+	if !failed.Requeued {
+		t.Error("The error did not say the search goes on")
+	}
+	waitFor(t, p2, types.MatchError)
+
+	// the matchmaker keeps running and matches the pair on the next tick
+	created := waitFor(t, p1, types.MatchCreated)
+	waitFor(t, p2, types.MatchCreated)
+
+	if players := games.players(created.RoomId); len(players) != 2 {
+		t.Errorf("Expected p1 and p2 in the game, got %v", players)
+	}
+}
+
+func TestSendKeepsFinalStatus(t *testing.T) {
+	ctx := context.Background()
+	uc := NewMatchmakerUseCase(&testContext{cfg: testConfig{}}, nil, nil, nil, nil)
+
+	responses := make(chan types.MatchResponse, 1)
+	uc.send(ctx, "p1", responses, types.MatchResponse{Status: types.MatchPending})
+	// the buffer is full, a further update is dropped
+	uc.send(ctx, "p1", responses, types.MatchResponse{Status: types.MatchPending})
+	uc.send(ctx, "p1", responses, types.MatchResponse{Status: types.MatchCreated, RoomId: "room"})
+
+	if response := <-responses; response.Status != types.MatchCreated {
+		t.Errorf("Expected the created game to replace the pending update, got %s", response.Status)
+	}
+}
+
+func waitFor(t *testing.T, responses <-chan types.MatchResponse, status types.ItemStatus) types.MatchResponse {
+	t.Helper()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case response := <-responses:
+			if response.Status == status {
+				return response
+			}
+		case <-deadline:
+			t.Fatalf("Timed out waiting for %s", status)
+			return types.MatchResponse{}
+		}
+	}
+}
+
+// TestMatchmakerManyPlayers queues players from many goroutines at once,
+// run it with -race. Some clients never read their responses and must not
+// hold up the others.
+func TestMatchmakerManyPlayers(t *testing.T) {
+	pod, games := startMatchmaker(t, testConfig{smallerTableAfter: time.Hour})
+
+	const players = 2000
+	// blocks of eight players share a rating far from the other blocks, so
+	// the six searching players of each block pair up
+	request := func(i int, responses chan types.MatchResponse) types.MatchChan {
+		return types.MatchChan{
+			PlayerId:   uuid.NewString(),
+			Mode:       "table",
+			Rating:     1000 * (i / 8),
+			MinSize:    2,
+			MaxSize:    2,
+			SentTime:   time.Now(),
+			ReturnChan: responses,
+		}
+	}
+
+	// two players of every block cancel right away. They cancel one after
+	// the other, so none of them has a partner before it is gone, and a
+	// cancelled search queued again by the matching loop shows up in a game
+	// with the players below.
+	cancelled := make(map[string]bool)
+	for i := 0; i < players; i += 4 {
+		search := request(i, make(chan types.MatchResponse, 4))
+		pod.queue <- search
+		pod.cancel <- types.MatchCancel{PlayerId: search.PlayerId}
+		cancelled[search.PlayerId] = true
+	}
+
+	var wg sync.WaitGroup
+	matched := make(chan bool, players)
+	for i := range players {
+		if i%4 == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses := make(chan types.MatchResponse, 4)
+			pod.queue <- request(i, responses)
+
+			// gone without cancelling
+			if i%4 == 1 {
+				return
+			}
+
+			deadline := time.After(time.Minute)
+			for {
+				select {
+				case response := <-responses:
+					if response.Status == types.MatchCreated {
+						matched <- true
+						return
+					}
+				case <-deadline:
+					matched <- false
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(matched)
+
+	for ok := range matched {
+		if !ok {
+			t.Fatal("Timed out waiting for a match")
+		}
+	}
+
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	for gameId, gamePlayers := range games.games {
+		for _, playerId := range gamePlayers {
+			if cancelled[playerId] {
+				t.Errorf("Cancelled player %s was matched into %s", playerId, gameId)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+// Ways to combine the ratings of party members, see PARTY_RATING.
+const (
+	PartyRatingAverage = "average"
+	PartyRatingMax     = "max"
+)
+
+type PartyUseCase struct {
+	ctx domain.Context
+}
+
+func NewPartyUseCase(ctx domain.Context) *PartyUseCase {
+	return &PartyUseCase{ctx: ctx}
+}
+
+// CreateParty makes the player the leader of a new party.
+func (uc *PartyUseCase) CreateParty(ctx context.Context, playerId string) (models.Party, error) {
+	repo := uc.ctx.Connection().PartyRepository()
+
+	partyId, err := repo.GetPlayerParty(ctx, playerId)
+	if err != nil {
+		return models.Party{}, err
+	}
+	if partyId != "" {
+		return models.Party{}, ErrAlreadyInParty
+	}
+
+	return repo.CreateParty(ctx, playerId)
+}
+
+// GetParty returns the party of the player.
+func (uc *PartyUseCase) GetParty(ctx context.Context, playerId string) (models.Party, error) {
+	repo := uc.ctx.Connection().PartyRepository()
+
+	partyId, err := repo.GetPlayerParty(ctx, playerId)
+	if err != nil {
+		return models.Party{}, err
+	}
+	if partyId == "" {
+		return models.Party{}, ErrNotInParty
+	}
+
+	party, err := repo.GetParty(ctx, partyId)
+	if errors.Is(err, redis.Nil) {
+		return models.Party{}, ErrNotInParty
+	}
+	return party, err
+}
+
+// Invite lets the leader invite another player into its party.
+func (uc *PartyUseCase) Invite(ctx context.Context, leaderId string, playerId string) error {
+	party, err := uc.GetParty(ctx, leaderId)
+	if err != nil {
+		return err
+	}
+	if party.LeaderId != leaderId {
+		return ErrNotPartyLeader
+	}
+	if playerId == leaderId {
+		return ErrAlreadyInParty
+	}
+	if len(party.Members) >= uc.ctx.Config().GetPartyMaxSize() {
+		return ErrPartyFull
+	}
+
+	return uc.ctx.Connection().PartyRepository().Invite(ctx, party.Id, playerId)
+}
+
+// Accept moves an invited player into the party. Concurrent accepts never
+// fill the party beyond its size.
+func (uc *PartyUseCase) Accept(ctx context.Context, playerId string, partyId string) (models.Party, error) {
+	repo := uc.ctx.Connection().PartyRepository()
+
+	party, err := repo.AcceptInvite(ctx, partyId, playerId, uc.ctx.Config().GetPartyMaxSize())
+	switch {
+	case errors.Is(err, repositories.ErrInParty):
+		return models.Party{}, ErrAlreadyInParty
+	case errors.Is(err, repositories.ErrNotInvited), errors.Is(err, redis.Nil):
+		// the party may be deleted right after the player joined it
+		return models.Party{}, ErrNotInvited
+	case errors.Is(err, repositories.ErrPartyFull):
+		return models.Party{}, ErrPartyFull
+	}
+	return party, err
+}
+
+// Leave takes the player out of its party. The member who joined first
+// after the leader takes over, and the party of the last member is deleted.
+// Returns the leader the party had before, whose search the player was part
+// of.
+func (uc *PartyUseCase) Leave(ctx context.Context, playerId string) (string, error) {
+	repo := uc.ctx.Connection().PartyRepository()
+
+	partyId, err := repo.GetPlayerParty(ctx, playerId)
+	if err != nil {
+		return "", err
+	}
+	if partyId == "" {
+		return "", ErrNotInParty
+	}
+
+	leaderId, err := repo.LeaveParty(ctx, partyId, playerId)
+	if errors.Is(err, redis.Nil) {
+		// another request took the player out first
+		return "", ErrNotInParty
+	}
+	return leaderId, err
+}
+
+// PartyRating combines the ratings of the members of a party into the
+// rating the party is matched by.
+func PartyRating(method string, ratings []int) int {
+	if len(ratings) == 0 {
+		return 0
+	}
+
+	switch method {
+	case PartyRatingMax:
+		return slices.Max(ratings)
+	default:
+		sum := 0
+		for _, rating := range ratings {
+			sum += rating
+		}
+		return sum / len(ratings)
+	}
+}
@@ -0,0 +1,186 @@
+package cases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/connection"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newPartyUseCase(t *testing.T) (*PartyUseCase, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewPartyUseCase(&testContext{cfg: testConfig{}, conn: connection.NewConnection(client)}), server
+}
+
+func TestPartyInviteAcceptLeave(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newPartyUseCase(t)
+
+	party, err := uc.CreateParty(ctx, "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.Accept(ctx, "friend", party.Id); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("Uninvited player joined the party: %v", err)
+	}
+	if err := uc.Invite(ctx, "friend", "leader"); !errors.Is(err, ErrNotInParty) {
+		t.Errorf("Player outside the party could invite: %v", err)
+	}
+
+	for _, playerId := range []string{"friend", "other"} {
+		if err := uc.Invite(ctx, "leader", playerId); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := uc.Accept(ctx, playerId, party.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := uc.Invite(ctx, "leader", "fourth"); !errors.Is(err, ErrPartyFull) {
+		t.Errorf("Expected the party to be full, got %v", err)
+	}
+	if err := uc.Invite(ctx, "friend", "fourth"); !errors.Is(err, ErrNotPartyLeader) {
+		t.Errorf("Member could invite: %v", err)
+	}
+
+	leaderId, err := uc.Leave(ctx, "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaderId != "leader" {
+		t.Errorf("Expected the search of leader to end, got %q", leaderId)
+	}
+
+	party, err = uc.GetParty(ctx, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if party.LeaderId != "friend" || len(party.Members) != 2 {
+		t.Errorf("Expected friend to lead the two remaining members, got %+v", party)
+	}
+	if _, err := uc.GetParty(ctx, "leader"); !errors.Is(err, ErrNotInParty) {
+		t.Errorf("Leader is still in the party: %v", err)
+	}
+}
+
+func TestPartyAcceptKeepsSize(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newPartyUseCase(t)
+
+	party, err := uc.CreateParty(ctx, "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invited := []string{"p1", "p2", "p3", "p4"}
+	for _, playerId := range invited {
+		if err := uc.Invite(ctx, "leader", playerId); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(invited))
+	for _, playerId := range invited {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.Accept(ctx, playerId, party.Id)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	joined := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case !errors.Is(err, ErrPartyFull):
+			t.Errorf("Expected the party to be full, got %v", err)
+		}
+	}
+	if joined != 2 {
+		t.Errorf("Expected 2 invited players to join, got %d", joined)
+	}
+
+	party, err = uc.GetParty(ctx, "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(party.Members) != 3 {
+		t.Errorf("Expected a party of 3, got %v", party.Members)
+	}
+}
+
+func TestPartyLeaveTogether(t *testing.T) {
+	ctx := context.Background()
+	uc, server := newPartyUseCase(t)
+
+	party, err := uc.CreateParty(ctx, "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, playerId := range []string{"friend", "other"} {
+		if err := uc.Invite(ctx, "leader", playerId); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := uc.Accept(ctx, playerId, party.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	members := []string{"leader", "friend", "other"}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(members)*2)
+	for _, playerId := range members {
+		// the second request of each player finds it gone
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := uc.Leave(ctx, playerId)
+				errs <- err
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	left := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			left++
+		case !errors.Is(err, ErrNotInParty):
+			t.Errorf("Expected the player to be gone, got %v", err)
+		}
+	}
+	if left != len(members) {
+		t.Errorf("Expected %d players to leave, got %d", len(members), left)
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("The empty party left keys behind: %v", keys)
+	}
+}
+
+func TestPartyRating(t *testing.T) {
+	ratings := []int{1000, 1200, 1400}
+	if rating := PartyRating(PartyRatingAverage, ratings); rating != 1200 {
+		t.Errorf("Expected an average of 1200, got %d", rating)
+	}
+	if rating := PartyRating(PartyRatingMax, ratings); rating != 1400 {
+		t.Errorf("Expected a max of 1400, got %d", rating)
+	}
+}
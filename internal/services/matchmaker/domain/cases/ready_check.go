@@ -0,0 +1,229 @@
+package cases
+
+import (
+	"context"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+)
+
+// readyCheck holds a full group out of the queue until every player
+// accepted the match.
+type readyCheck struct {
+	mode types.Mode
+	// entries are the queued players and party leaders, parties the other
+	// members of each entry and players every seat at the table.
+	entries  []string
+	parties  map[string][]string
+	players  []string
+	deadline time.Time
+	accepted map[string]bool
+	declined bool
+}
+
+// startReadyCheck sends match_found with the deadline to every player of
+// the group.
+func (uc *MatchmakerUseCase) startReadyCheck(ctx context.Context, check *readyCheck) {
+	check.deadline = time.Now().Add(uc.ctx.Config().GetReadyCheckTimeout())
+
+	uc.checks = append(uc.checks, check)
+	for _, player := range check.players {
+		uc.checking[player] = check
+	}
+
+	response := types.MatchResponse{
+		Status:    types.MatchFound,
+		GroupSize: len(check.players),
+		Deadline:  check.deadline,
+	}
+	for _, entry := range check.entries {
+		if queued, ok := uc.searches[entry]; ok {
+			uc.respond(ctx, queued, response)
+		}
+	}
+}
+
+// answer records the answer of a player to its ready-check, if it has one.
+func (uc *MatchmakerUseCase) answer(accept types.MatchAccept) {
+	check, ok := uc.checking[accept.PlayerId]
+	if !ok {
+		return
+	}
+
+	if accept.Accept {
+		check.accepted[accept.PlayerId] = true
+	} else {
+		check.declined = true
+	}
+}
+
+// resolveReadyChecks creates the games every player accepted and breaks up
+// the groups where someone declined or let the deadline pass.
+func (uc *MatchmakerUseCase) resolveReadyChecks(ctx context.Context) error {
+	now := time.Now()
+
+	pending := uc.checks[:0]
+	for _, check := range uc.checks {
+		switch {
+		case len(check.accepted) == len(check.players):
+			if !uc.createGame(ctx, check) {
+				pending = append(pending, check)
+				continue
+			}
+		case check.declined || now.After(check.deadline):
+			if err := uc.failReadyCheck(ctx, check); err != nil {
+				return err
+			}
+		default:
+			pending = append(pending, check)
+			continue
+		}
+
+		for _, player := range check.players {
+			delete(uc.checking, player)
+		}
+	}
+	uc.checks = pending
+	return nil
+}
+
+// failReadyCheck removes and penalizes the players who did not accept, with
+// their parties. The others are queued again with their original search
+// time, so they keep the rating range they had already reached.
+func (uc *MatchmakerUseCase) failReadyCheck(ctx context.Context, check *readyCheck) error {
+	repo := uc.ctx.Connection().MatchmakerRepository(check.mode.Name)
+	penalties := uc.ctx.Connection().PenaltyRepository()
+
+	for _, entry := range check.entries {
+		declined := false
+		for _, player := range append([]string{entry}, check.parties[entry]...) {
+			if check.accepted[player] {
+				continue
+			}
+			declined = true
+
+			err := penalties.Penalize(ctx, player, uc.ctx.Config().GetDeclinePenalty())
+			if err != nil {
+				uc.ctx.Logger().ErrorContext(ctx, "Failed to penalize player", "player_id", player, "error", err.Error())
+			}
+		}
+
+		// an empty status queues the entry again on the next tick
+		err := repo.SetPlayerStatus(ctx, entry, models.StatusEmpty)
+		if err != nil {
+			return err
+		}
+
+		queued, ok := uc.searches[entry]
+		if !ok {
+			continue
+		}
+		if !declined {
+			uc.respond(ctx, queued, types.MatchResponse{Status: types.MatchPending})
+			continue
+		}
+
+		uc.respond(ctx, queued, types.MatchResponse{Status: types.MatchDeclined})
+		uc.forget(ctx, queued)
+	}
+	return nil
+}
+
+// createGame creates the game of the group and sends it to every player.
+// When the game service fails, the players are queued again without a
+// penalty. Reports false when the replica is no longer the leader, the group
+// is left to the next leader then.
+func (uc *MatchmakerUseCase) createGame(ctx context.Context, check *readyCheck) bool {
+	repo := uc.ctx.Connection().MatchmakerRepository(check.mode.Name)
+
+	if !uc.renewLeadership(ctx) {
+		return false
+	}
+
+	gameId, err := uc.gameClient.CreateGame(ctx, &game.CreateGameRequest{
+		UserIds: check.players,
+		Settings: &game.GameSettings{
+			Mode:     check.mode.Name,
+			DeckSize: int32(check.mode.DeckSize),
+			Ranked:   check.mode.Ranked,
+		},
+	})
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to create game", "mode", check.mode.Name, "players", len(check.players), "error", err.Error())
+		uc.requeue(ctx, check)
+		return true
+	}
+
+	response := types.MatchResponse{
+		Status:    types.MatchCreated,
+		RoomId:    gameId.GameId,
+		GroupSize: len(check.players),
+	}
+
+	for _, entry := range check.entries {
+		err := repo.SetPlayerStatus(ctx, entry, models.StatusEmpty)
+		if err != nil {
+			// the game exists, the player is still sent to it
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to reset player status", "player_id", entry, "error", err.Error())
+		}
+
+		if queued, ok := uc.searches[entry]; ok {
+			uc.respond(ctx, queued, response)
+			uc.forget(ctx, queued)
+		}
+	}
+	return true
+}
+
+// renewLeadership extends the leadership right before a game is created, so
+// a replica that lost it during the tick does not create a game the new
+// leader creates as well.
+func (uc *MatchmakerUseCase) renewLeadership(ctx context.Context) bool {
+	elected, err := uc.ctx.Connection().LeaderRepository().Acquire(ctx, uc.pod, uc.ctx.Config().GetLeaderTTL())
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to renew leadership", "pod", uc.pod, "error", err.Error())
+		return false
+	}
+	if !elected {
+		uc.ctx.Logger().InfoContext(ctx, "Matchmaker leadership lost before creating a game", "pod", uc.pod)
+	}
+	return elected
+}
+
+// requeue queues the group of a game that could not be created again with
+// the original search times, and tells its players.
+func (uc *MatchmakerUseCase) requeue(ctx context.Context, check *readyCheck) {
+	repo := uc.ctx.Connection().MatchmakerRepository(check.mode.Name)
+
+	for _, entry := range check.entries {
+		// an empty status queues the entry again on the next tick
+		err := repo.SetPlayerStatus(ctx, entry, models.StatusEmpty)
+		if err != nil {
+			uc.ctx.Logger().ErrorContext(ctx, "Failed to reset player status", "player_id", entry, "error", err.Error())
+		}
+
+		if queued, ok := uc.searches[entry]; ok {
+			uc.respond(ctx, queued, types.MatchResponse{Status: types.MatchError, Requeued: true})
+		}
+	}
+}
+
+// forget ends the search of the player.
+func (uc *MatchmakerUseCase) forget(ctx context.Context, player types.MatchChan) {
+	delete(uc.searches, player.PlayerId)
+
+	err := uc.ctx.Connection().SearchRepository().DeleteSearch(ctx, player.PlayerId)
+	if err != nil {
+		uc.ctx.Logger().ErrorContext(ctx, "Failed to delete search", "player_id", player.PlayerId, "error", err.Error())
+	}
+}
+
+// dropReadyChecks forgets the ready-checks after the replica lost the
+// leadership. The groups are already out of the queues, so the new leader
+// queues their players again.
+func (uc *MatchmakerUseCase) dropReadyChecks() {
+	uc.checks = nil
+	clear(uc.checking)
+}
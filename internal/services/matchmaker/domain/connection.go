@@ -1,7 +1,23 @@
 package domain
 
-import "github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+import (
+	"context"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/repositories"
+)
 
 type Connection interface {
-	MatchmakerRepository() repositories.MatchmakerRepository
+	// MatchmakerRepository holds the queues of the named mode.
+	MatchmakerRepository(mode string) repositories.MatchmakerRepository
+	PartyRepository() repositories.PartyRepository
+	LobbyRepository() repositories.LobbyRepository
+	PenaltyRepository() repositories.PenaltyRepository
+	SearchRepository() repositories.SearchRepository
+	LeaderRepository() repositories.LeaderRepository
+	Tickets() Tickets
+}
+
+// Tickets redeems the single-use tickets issued by the auth service.
+type Tickets interface {
+	Consume(ctx context.Context, ticket, scope string) (playerId string, err error)
 }
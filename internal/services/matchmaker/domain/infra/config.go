@@ -1,5 +1,12 @@
 package infra
 
+import (
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
+)
+
 type Config interface {
 	GetJWTPublic() string
 	GetPort() string
@@ -9,4 +16,21 @@ type Config interface {
 	GetPodName() string
 	GetNamespace() string
 	GetLogLevel() string
+	GetTracing() tracing.Config
+	GetAllowedOrigins() []string
+	GetMaxFrameSize() int
+	GetMessageBurst() int
+	GetMessageInterval() time.Duration
+	GetMaxViolations() int
+	GetSendBufferSize() int
+	GetPingInterval() time.Duration
+	GetPongWait() time.Duration
+	GetSmallerTableAfter() time.Duration
+	GetModes() []types.Mode
+	GetDefaultMode() string
+	GetReadyCheckTimeout() time.Duration
+	GetDeclinePenalty() time.Duration
+	GetLeaderTTL() time.Duration
+	GetPartyMaxSize() int
+	GetPartyRating() string
 }
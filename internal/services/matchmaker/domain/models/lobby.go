@@ -0,0 +1,47 @@
+package models
+
+// LobbySettings are chosen by the host and passed to the game.
+type LobbySettings struct {
+	MaxPlayers int  `json:"max_players"`
+	DeckSize   int  `json:"deck_size"`
+	Public     bool `json:"public"`
+}
+
+type LobbyMember struct {
+	PlayerId string `json:"player_id"`
+	Ready    bool   `json:"ready"`
+}
+
+// Lobby is a private table players join by its invite code. Members lists
+// the players in the order they joined, the host included.
+type Lobby struct {
+	Code     string        `json:"code"`
+	HostId   string        `json:"host_id"`
+	Settings LobbySettings `json:"settings"`
+	Members  []LobbyMember `json:"members"`
+	// Starting is set while the game of the lobby is created.
+	Starting bool `json:"starting"`
+}
+
+// Member returns the index of the player in Members, or -1.
+func (l *Lobby) Member(playerId string) int {
+	for i, member := range l.Members {
+		if member.PlayerId == playerId {
+			return i
+		}
+	}
+	return -1
+}
+
+const (
+	LobbyUpdated = "updated"
+	LobbyStarted = "started"
+	LobbyClosed  = "closed"
+)
+
+// LobbyEvent is published to everyone watching the lobby.
+type LobbyEvent struct {
+	Type   string `json:"type"`
+	Lobby  Lobby  `json:"lobby"`
+	GameId string `json:"game_id,omitempty"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Party is a group of players who queue together. Members lists the leader
+// first, then the others in the order they joined.
+type Party struct {
+	Id       string
+	LeaderId string
+	Members  []string
+}
@@ -12,4 +12,5 @@ type RedisPlayer struct {
 	Status PlayerStatus
 	Id     string
 	Gid    int
+	Size   int
 }
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// LeaderRepository elects the one replica that runs the matching loop.
+type LeaderRepository interface {
+	// Acquire takes the leadership for ttl, or extends it when the replica
+	// already holds it, and reports whether the replica is the leader.
+	Acquire(ctx context.Context, pod string, ttl time.Duration) (bool, error)
+	// Release gives up the leadership if the replica holds it.
+	Release(ctx context.Context, pod string) error
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+)
+
+type LobbyRepository interface {
+	// CreateLobby stores the lobby under a new invite code.
+	CreateLobby(ctx context.Context, lobby models.Lobby) (models.Lobby, error)
+	// GetLobby returns redis.Nil when the lobby does not exist.
+	GetLobby(ctx context.Context, code string) (models.Lobby, error)
+	// GetPlayerLobby returns the code of the lobby of the player, or an empty
+	// string when the player is not in a lobby.
+	GetPlayerLobby(ctx context.Context, playerId string) (string, error)
+	// UpdateLobby applies update to the stored lobby, retrying when another
+	// update got there first.
+	UpdateLobby(ctx context.Context, code string, update func(lobby *models.Lobby) error) (models.Lobby, error)
+	DeleteLobby(ctx context.Context, code string) error
+	ListPublicLobbies(ctx context.Context) ([]models.Lobby, error)
+	Publish(ctx context.Context, code string, event models.LobbyEvent) error
+	// Subscribe delivers the events of the lobby until close is called.
+	Subscribe(ctx context.Context, code string) (events <-chan models.LobbyEvent, close func() error, err error)
+}
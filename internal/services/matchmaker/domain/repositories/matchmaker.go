@@ -2,23 +2,39 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrConflict is returned when a player or group changed before it could be
+// moved, e.g. because another group took the player first. Nothing was
+// written.
+var ErrConflict = errors.New("matchmaker: player or group changed")
+
 type MatchmakerRepository interface {
 	GetPlayer(ctx context.Context, playerId string) (models.RedisPlayer, error)
-	AddPlayer(ctx context.Context, playerId string, score int) (models.RedisPlayer, error)
+	// AddPlayer queues the player for tables of size players. The members of
+	// party take seats at the same table and are never queued on their own.
+	// Returns ErrConflict when the player no longer has a search.
+	AddPlayer(ctx context.Context, playerId string, score int, size int, party []string) (models.RedisPlayer, error)
+	// GetSeats is the number of seats the player takes, one for itself and
+	// one for every party member queued with it.
+	GetSeats(ctx context.Context, playerId string) (int, error)
+	GetPartyMembers(ctx context.Context, playerId string) ([]string, error)
 	RemovePlayer(ctx context.Context, playerId string) error
-	ListPlayersRange(ctx context.Context, low int, high int) ([]redis.Z, error)
-	CountGroups(ctx context.Context) (int, error)
-	ListGroupsRange(ctx context.Context, low int, high int) ([]redis.Z, error)
-	AddGroup(ctx context.Context, players []redis.Z) error
-	AddToGroup(ctx context.Context, groupId int, player redis.Z) error
+	ListPlayersRange(ctx context.Context, size int, low int, high int) ([]redis.Z, error)
+	CountGroups(ctx context.Context, size int) (int, error)
+	ListGroupsRange(ctx context.Context, size int, low int, high int) ([]redis.Z, error)
+	AddGroup(ctx context.Context, size int, players []redis.Z) error
+	AddToGroup(ctx context.Context, size int, groupId int, player redis.Z) error
+	// GetGroupLen counts the seats taken in the group.
 	GetGroupLen(ctx context.Context, groupId int) (int, error)
 	GetGrouppedPlayers(ctx context.Context, groupId int, amount int) ([]string, error)
-	RemoveGroup(ctx context.Context, groupId int) error
+	// RemoveGroup takes the group out of its queue and reports whether it
+	// was still queued, so only one replica claims a group.
+	RemoveGroup(ctx context.Context, size int, groupId int) (bool, error)
 	SetPlayerStatus(ctx context.Context, playerId string, status models.PlayerStatus) error
 	ParseGroupId(groupString string) (int, error)
 }
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/models"
+)
+
+// Reasons AcceptInvite turns a player away. Nothing was written.
+var (
+	ErrInParty    = errors.New("party: player is already in a party")
+	ErrNotInvited = errors.New("party: player is not invited")
+	ErrPartyFull  = errors.New("party: party is full")
+)
+
+type PartyRepository interface {
+	CreateParty(ctx context.Context, leaderId string) (models.Party, error)
+	// GetParty returns redis.Nil when the party does not exist.
+	GetParty(ctx context.Context, partyId string) (models.Party, error)
+	// GetPlayerParty returns the id of the party of the player, or an empty
+	// string when the player is not in a party.
+	GetPlayerParty(ctx context.Context, playerId string) (string, error)
+	Invite(ctx context.Context, partyId string, playerId string) error
+	// AcceptInvite moves an invited player who is in no party into the party
+	// if it has fewer than maxSize members, and returns the party.
+	AcceptInvite(ctx context.Context, partyId string, playerId string, maxSize int) (models.Party, error)
+	// LeaveParty takes the player out of the party and returns the leader the
+	// party had before. Returns redis.Nil when the player is not a member.
+	LeaveParty(ctx context.Context, partyId string, playerId string) (string, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// PenaltyRepository keeps players who declined a found match out of the
+// queues for a while.
+type PenaltyRepository interface {
+	Penalize(ctx context.Context, playerId string, duration time.Duration) error
+	// PenaltyLeft is zero when the player may queue.
+	PenaltyLeft(ctx context.Context, playerId string) (time.Duration, error)
+}
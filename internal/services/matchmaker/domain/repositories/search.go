@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+)
+
+// SearchRepository shares the searches between the matchmaker replicas.
+// Every replica stores the searches of its WebSocket clients, the elected
+// replica runs the queues over all of them and notifies the replicas owning
+// the players.
+type SearchRepository interface {
+	SaveSearch(ctx context.Context, search types.MatchChan) error
+	DeleteSearch(ctx context.Context, playerId string) error
+	// GetSearch returns redis.Nil when the player is not searching.
+	GetSearch(ctx context.Context, playerId string) (types.MatchChan, error)
+	ListSearches(ctx context.Context) ([]types.MatchChan, error)
+	// SetOwner routes the responses for the player to the replica.
+	SetOwner(ctx context.Context, playerId string, pod string) error
+	DeleteOwner(ctx context.Context, playerId string) error
+	// Heartbeat marks the replica alive for ttl. Searches of replicas that
+	// stopped beating are dropped by the matching loop.
+	Heartbeat(ctx context.Context, pod string, ttl time.Duration) error
+	IsAlive(ctx context.Context, pod string) (bool, error)
+	// Notify publishes the response to the replicas owning the players.
+	Notify(ctx context.Context, playerIds []string, response types.MatchResponse) error
+	// Subscribe delivers the notifications for the players of the replica
+	// until close is called.
+	Subscribe(ctx context.Context, pod string) (notifications <-chan types.Notification, close func() error, err error)
+	// PublishAnswer passes the answer to a ready-check to the matching loop.
+	PublishAnswer(ctx context.Context, answer types.MatchAccept) error
+	SubscribeAnswers(ctx context.Context) (answers <-chan types.MatchAccept, close func() error, err error)
+}
@@ -2,6 +2,12 @@ package types
 
 import "time"
 
+// Tables seat from MinTableSize to MaxTableSize players.
+const (
+	MinTableSize = 2
+	MaxTableSize = 6
+)
+
 type ItemStatus int
 
 const (
@@ -9,6 +15,10 @@ const (
 	MatchFoundGroup
 	MatchCreated
 	MatchError
+	MatchFound
+	MatchDeclined
+	// MatchCancelled ends the search of a party after one of its players left.
+	MatchCancelled
 )
 
 func (s ItemStatus) String() string {
@@ -21,6 +31,12 @@ func (s ItemStatus) String() string {
 		return "found_group"
 	case MatchError:
 		return "error"
+	case MatchFound:
+		return "match_found"
+	case MatchDeclined:
+		return "declined"
+	case MatchCancelled:
+		return "cancelled"
 	}
 	return "unknown"
 }
@@ -29,16 +45,59 @@ type MatchResponse struct {
 	Status    ItemStatus
 	RoomId    string
 	GroupSize int
-	Error     error
+	// Deadline to accept a found match.
+	Deadline time.Time
+	Error    error
+	// Requeued marks a MatchError after which the search goes on.
+	Requeued bool
 }
 
+// MatchCancel ends the search of a player. A party member passes its
+// Leader, as leaving ends the search of the whole party.
 type MatchCancel struct {
 	PlayerId string
+	Leader   string
+	// PartyLeft is set when the player left the party of Leader. Its
+	// WebSocket stays open and is told that the search was cancelled.
+	PartyLeft bool
+}
+
+// MatchAccept answers the ready-check of a found match.
+type MatchAccept struct {
+	PlayerId string
+	Accept   bool
 }
 
+// MatchChan queues a player of Mode for a table of MaxSize players. Once the
+// player has waited long enough, a table of at least MinSize players is
+// accepted.
+//
+// ReturnChan must be buffered, responses are dropped rather than waiting for
+// a client that fell behind. The matchmaker also receives from it to make
+// room for the responses a client must not miss.
+//
+// Pod is the matchmaker replica holding the WebSocket of the player, the
+// replica running the matching loop sends the responses there.
+//
+// A party leader is queued with the other members in Party and the combined
+// rating of the party. Members pass their Leader instead and only receive
+// the responses of the search of the leader.
 type MatchChan struct {
 	PlayerId   string
+	Mode       string
 	Rating     int
+	MinSize    int
+	MaxSize    int
+	Party      []string
+	Leader     string
 	SentTime   time.Time
-	ReturnChan chan<- MatchResponse
+	Pod        string
+	ReturnChan chan MatchResponse `json:"-"`
+}
+
+// Notification carries a response of the matching loop to the replica
+// holding the WebSockets of the players.
+type Notification struct {
+	PlayerIds []string
+	Response  MatchResponse
 }
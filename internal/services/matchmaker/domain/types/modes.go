@@ -0,0 +1,62 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Mode is a named matchmaking queue. Players of different modes are never
+// matched together.
+type Mode struct {
+	Name    string `json:"name"`
+	MinSize int    `json:"min_size"`
+	MaxSize int    `json:"max_size"`
+	// DeckSize and Ranked are passed to the game, which only stores Ranked
+	// as a label.
+	DeckSize int  `json:"deck_size"`
+	Ranked   bool `json:"ranked"`
+	// The rating range searched widens by RangeBy every RangeAfter.
+	RangeAfter time.Duration `json:"-"`
+	RangeBy    int           `json:"range_by"`
+}
+
+// DeckSizes are the decks the game service can deal.
+var DeckSizes = []int{24, 36, 52}
+
+const DefaultDeckSize = 36
+
+// HandSize is the number of cards dealt to every player, so a deck seats at
+// most its size divided by HandSize players.
+const HandSize = 6
+
+// DefaultModes are used when the config defines no modes.
+var DefaultModes = []Mode{
+	{Name: "classic-2p", MinSize: 2, MaxSize: 2, DeckSize: 36, Ranked: true, RangeAfter: 5 * time.Second, RangeBy: 100},
+	{Name: "casual-2p", MinSize: 2, MaxSize: 2, DeckSize: 36, RangeAfter: 2 * time.Second, RangeBy: 300},
+	{Name: "classic-6p", MinSize: 2, MaxSize: 6, DeckSize: 36, Ranked: true, RangeAfter: 5 * time.Second, RangeBy: 100},
+	{Name: "quick-24card", MinSize: 2, MaxSize: 4, DeckSize: 24, RangeAfter: 2 * time.Second, RangeBy: 300},
+}
+
+// UnmarshalJSON reads range_after as a duration string such as "5s".
+func (m *Mode) UnmarshalJSON(data []byte) error {
+	type mode Mode
+	var decoded struct {
+		mode
+		RangeAfter string `json:"range_after"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*m = Mode(decoded.mode)
+	if decoded.RangeAfter == "" {
+		return nil
+	}
+
+	rangeAfter, err := time.ParseDuration(decoded.RangeAfter)
+	if err != nil {
+		return err
+	}
+	m.RangeAfter = rangeAfter
+	return nil
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/alecthomas/kong"
+
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 )
 
 type Config struct {
@@ -15,6 +23,31 @@ type Config struct {
 	PodName    string `help:"K8s pod name" env:"POD_NAME" default:"unknown"`
 	Namespace  string `help:"K8s namespace" env:"NAMESPACE" default:"unknown"`
 	LogLevel   string `help:"Log level (debug, info, warn, error)"    env:"LOG_LEVEL" default:"info"`
+
+	AllowedOrigins  []string      `help:"Origins allowed to open WebSockets, * for any"      env:"WS_ALLOWED_ORIGINS"`
+	MaxFrameSize    int           `help:"Largest inbound WebSocket frame in bytes"           env:"WS_MAX_FRAME_SIZE"   default:"1024"`
+	MessageBurst    int           `help:"Messages a client may send in a burst"              env:"WS_MESSAGE_BURST"    default:"5"`
+	MessageInterval time.Duration `help:"Time to regain one message of the burst"            env:"WS_MESSAGE_INTERVAL" default:"1s"`
+	MaxViolations   int           `help:"Rate limit violations before the client is dropped" env:"WS_MAX_VIOLATIONS"   default:"5"`
+	SendBufferSize  int           `help:"Responses buffered per searching client"            env:"WS_SEND_BUFFER_SIZE" default:"16"`
+	PingInterval    time.Duration `help:"Interval between WebSocket pings"                   env:"WS_PING_INTERVAL"    default:"25s"`
+	PongWait        time.Duration `help:"Time to wait for a pong before dropping the client" env:"WS_PONG_WAIT"        default:"60s"`
+
+	SmallerTableAfter time.Duration `help:"Wait after which a table may start below its size"   env:"SMALLER_TABLE_AFTER" default:"30s"`
+	ModesJSON         string        `help:"JSON list of matchmaking modes, built-in modes if empty" env:"MATCH_MODES"`
+	DefaultMode       string        `help:"Mode of players who do not request one"              env:"DEFAULT_MODE"        default:"classic-2p"`
+
+	ReadyCheckTimeout time.Duration `help:"Time players have to accept a found match, 0 skips the ready-check" env:"READY_CHECK_TIMEOUT" default:"15s"`
+	DeclinePenalty    time.Duration `help:"Time a player who declined may not queue"            env:"DECLINE_PENALTY"     default:"1m"`
+
+	LeaderTTL time.Duration `help:"Time the replica running the matching loop holds it without renewing" env:"LEADER_TTL" default:"5s"`
+
+	PartyMaxSize int    `help:"Most players in a party"                               env:"PARTY_MAX_SIZE" default:"3"`
+	PartyRating  string `help:"Rating of a party from its members (average, max)"   env:"PARTY_RATING"   default:"average" enum:"average,max"`
+
+	modes []types.Mode
+
+	Tracing tracing.Config `embed:""`
 }
 
 func Make() *Config {
@@ -30,6 +63,10 @@ func Make() *Config {
 		log.Panic(err)
 	}
 
+	if err := cfg.parseModes(); err != nil {
+		log.Panic(err)
+	}
+
 	return cfg
 }
 
@@ -64,3 +101,115 @@ func (s *Config) GetNamespace() string {
 func (s *Config) GetLogLevel() string {
 	return s.LogLevel
 }
+
+func (s *Config) GetTracing() tracing.Config {
+	return s.Tracing
+}
+
+func (s *Config) GetAllowedOrigins() []string {
+	return s.AllowedOrigins
+}
+
+func (s *Config) GetMaxFrameSize() int {
+	return s.MaxFrameSize
+}
+
+func (s *Config) GetMessageBurst() int {
+	return s.MessageBurst
+}
+
+func (s *Config) GetMessageInterval() time.Duration {
+	return s.MessageInterval
+}
+
+func (s *Config) GetMaxViolations() int {
+	return s.MaxViolations
+}
+
+func (s *Config) GetSendBufferSize() int {
+	return s.SendBufferSize
+}
+
+func (s *Config) GetPingInterval() time.Duration {
+	return s.PingInterval
+}
+
+func (s *Config) GetPongWait() time.Duration {
+	return s.PongWait
+}
+
+func (s *Config) GetSmallerTableAfter() time.Duration {
+	return s.SmallerTableAfter
+}
+
+func (s *Config) GetModes() []types.Mode {
+	if s.modes == nil {
+		return types.DefaultModes
+	}
+	return s.modes
+}
+
+func (s *Config) GetDefaultMode() string {
+	return s.DefaultMode
+}
+
+func (s *Config) GetReadyCheckTimeout() time.Duration {
+	return s.ReadyCheckTimeout
+}
+
+func (s *Config) GetDeclinePenalty() time.Duration {
+	return s.DeclinePenalty
+}
+
+func (s *Config) GetLeaderTTL() time.Duration {
+	return s.LeaderTTL
+}
+
+func (s *Config) GetPartyMaxSize() int {
+	return s.PartyMaxSize
+}
+
+func (s *Config) GetPartyRating() string {
+	return s.PartyRating
+}
+
+// parseModes reads MATCH_MODES and checks that every mode can be played.
+// Modes without a deck size use the classic deck.
+func (s *Config) parseModes() error {
+	var modes []types.Mode
+	if s.ModesJSON == "" {
+		modes = slices.Clone(types.DefaultModes)
+	} else if err := json.Unmarshal([]byte(s.ModesJSON), &modes); err != nil {
+		return fmt.Errorf("MATCH_MODES: %w", err)
+	}
+
+	defaultFound := false
+	for i, mode := range modes {
+		if mode.DeckSize == 0 {
+			mode.DeckSize = types.DefaultDeckSize
+			modes[i] = mode
+		}
+		if mode.Name == "" {
+			return errors.New("MATCH_MODES: mode without a name")
+		}
+		if mode.MinSize < types.MinTableSize || mode.MaxSize > types.MaxTableSize || mode.MinSize > mode.MaxSize {
+			return fmt.Errorf("MATCH_MODES: mode %s seats %d to %d players", mode.Name, mode.MinSize, mode.MaxSize)
+		}
+		if !slices.Contains(types.DeckSizes, mode.DeckSize) {
+			return fmt.Errorf("MATCH_MODES: mode %s deals an unknown deck of %d cards", mode.Name, mode.DeckSize)
+		}
+		if mode.MaxSize*types.HandSize > mode.DeckSize {
+			return fmt.Errorf("MATCH_MODES: mode %s seats %d players at a deck of %d cards", mode.Name, mode.MaxSize, mode.DeckSize)
+		}
+		if mode.RangeAfter <= 0 || mode.RangeBy <= 0 {
+			return fmt.Errorf("MATCH_MODES: mode %s needs a positive range_after and range_by", mode.Name)
+		}
+		defaultFound = defaultFound || mode.Name == s.DefaultMode
+	}
+	if !defaultFound {
+		return fmt.Errorf("DEFAULT_MODE: unknown mode %s", s.DefaultMode)
+	}
+
+	s.modes = modes
+	return nil
+}
@@ -45,4 +45,20 @@ var (
 		},
 		[]string{"pod", "namespace"},
 	)
+
+	WebsocketViolations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "matchmaker_websocket_violations_total",
+			Help: "Total number of inbound WebSocket messages over the rate or size limit",
+		},
+		[]string{"pod", "namespace", "reason"},
+	)
+
+	WebsocketAbuseDisconnects = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "matchmaker_websocket_abuse_disconnects_total",
+			Help: "Total number of clients disconnected after repeated violations",
+		},
+		[]string{"pod", "namespace"},
+	)
 )
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"sync"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/players/core"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 
 	_ "github.com/MommusWinner/MicroDurak/internal/services/players/delivery/http/docs" // для swagger документации
 )
@@ -21,6 +23,13 @@ func main() {
 	var wg sync.WaitGroup
 
 	di := core.NewDi()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "players", di.Ctx.Config().GetTracing())
+	if err != nil {
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
+
 	server := core.NewHttpServer(di.Ctx, di.PlayerHandler)
 	grpcServer := core.NewGrpcServer(di.Ctx, di.PlayerUseCase, di.MatchUseCase)
 
@@ -2,12 +2,12 @@ package core
 
 import (
 	"log/slog"
-	"os"
 
 	"github.com/MommusWinner/MicroDurak/internal/services/players/connection"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/domain/infra"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/infra/config"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
 	"github.com/alecthomas/kong"
 )
 
@@ -62,7 +62,11 @@ func (c *Ctx) Make() domain.Context {
 
 func InitCtx() *Ctx {
 	cfg := config.Make()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger, err := logging.New("players", cfg.GetLogLevel())
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
 
 	db := connection.Make(cfg)
 
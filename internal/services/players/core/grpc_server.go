@@ -7,6 +7,7 @@ import (
 	grpc2 "github.com/MommusWinner/MicroDurak/internal/services/players/delivery/grpc"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/domain"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/domain/cases"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"google.golang.org/grpc"
 )
 
@@ -21,7 +22,7 @@ func NewGrpcServer(ctx domain.Context, playerUseCase *cases.PlayerUseCase, match
 		panic("failed to listen: " + err.Error())
 	}
 
-	var opts []grpc.ServerOption
+	opts := []grpc.ServerOption{tracing.ServerOption()}
 	grpcServer := grpc.NewServer(opts...)
 
 	service := grpc2.NewPlayerService(ctx, playerUseCase, matchUseCase)
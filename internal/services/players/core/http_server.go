@@ -3,6 +3,8 @@ package core
 import (
 	"github.com/MommusWinner/MicroDurak/internal/services/players/delivery/http"
 	"github.com/MommusWinner/MicroDurak/internal/services/players/domain"
+	"github.com/MommusWinner/MicroDurak/lib/logging"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"github.com/MommusWinner/MicroDurak/lib/validate"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
@@ -22,6 +24,8 @@ type Server interface {
 func NewHttpServer(ctx domain.Context, playerHandler *http.PlayerHandler) Server {
 	app := echo.New()
 	app.Validator = validate.NewHttpValidator(validator.New())
+	app.Use(tracing.Middleware("players"))
+	app.Use(logging.Middleware(ctx.Logger()))
 
 	app.GET("/swagger/*", echoSwagger.WrapHandler)
 
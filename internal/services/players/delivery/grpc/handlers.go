@@ -27,7 +27,7 @@ func NewPlayerService(ctx domain.Context, playerUseCase *cases.PlayerUseCase, ma
 }
 
 func (ps *PlayerService) CreatePlayer(ctx context.Context, req *players.CreatePlayerRequest) (*players.CreatePlayerReply, error) {
-	resp, err := ps.playerUseCase.Create(props.CreatePlayerReq{Name: req.Name, Age: int(req.Age)})
+	resp, err := ps.playerUseCase.Create(ctx, props.CreatePlayerReq{Name: req.Name, Age: int(req.Age)})
 
 	if err != nil {
 		return nil, err
@@ -42,7 +42,7 @@ func (ps *PlayerService) GetPlayer(ctx context.Context, req *players.GetPlayerRe
 		return nil, status.New(codes.InvalidArgument, "player_id is not uuid").Err()
 	}
 
-	resp, err := ps.playerUseCase.GetById(props.GetPlayerByIdReq{Id: playerId})
+	resp, err := ps.playerUseCase.GetById(ctx, props.GetPlayerByIdReq{Id: playerId})
 	if err != nil {
 		return nil, err
 	}
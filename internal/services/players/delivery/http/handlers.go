@@ -71,7 +71,7 @@ var internalServerError = echo.NewHTTPError(http.StatusInternalServerError, "Int
 // @Failure 500 "Internal server error"
 // @Router /players [get]
 func (h *PlayerHandler) GetAll(c echo.Context) error {
-	resp, err := h.playerUseCase.GetAll(props.GetAllPlayersReq{})
+	resp, err := h.playerUseCase.GetAll(c.Request().Context(), props.GetAllPlayersReq{})
 
 	if err != nil {
 		if errors.Is(err, cases.ErrNoPlayers) {
@@ -112,7 +112,7 @@ func (h *PlayerHandler) GetById(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid player ID format")
 	}
 
-	resp, err := h.playerUseCase.GetById(props.GetPlayerByIdReq{Id: playerId})
+	resp, err := h.playerUseCase.GetById(c.Request().Context(), props.GetPlayerByIdReq{Id: playerId})
 
 	if err != nil {
 		return internalServerError
@@ -148,8 +148,7 @@ func (h *PlayerHandler) CreateMatch(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
 	}
 	if err := c.Validate(req); err != nil {
-		h.ctx.Logger().Info("Hello")
-		h.ctx.Logger().Info(err.Error())
+		h.ctx.Logger().InfoContext(c.Request().Context(), "Invalid match result", "error", err.Error())
 		return err
 	}
 
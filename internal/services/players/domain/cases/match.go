@@ -25,7 +25,7 @@ func NewMatchUseCase(ctx domain.Context) *MatchUseCase {
 func (uc *MatchUseCase) CreateMatchResult(ctx context.Context, req *props.CreateMatchResutlReq) (resp *props.CreateMatchResutlResp, err error) {
 	if len(req.PlayerPlacements) == 0 {
 		err = ErrNoPlayers
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 	}
 
 	playerStats := make([]models.PlayerStats, len(req.PlayerPlacements))
@@ -33,16 +33,16 @@ func (uc *MatchUseCase) CreateMatchResult(ctx context.Context, req *props.Create
 	for i, placement := range req.PlayerPlacements {
 		id, err := uuid.Parse(placement.Id)
 		if err != nil {
-			uc.ctx.Logger().Error("Unprocessable player id", "player_id", placement.Id)
+			uc.ctx.Logger().ErrorContext(ctx, "Unprocessable player id", "player_id", placement.Id)
 			return nil, ErrUnprocessableId
 		}
 		user, err := uc.ctx.Connection().UserRepository().GetById(ctx, id)
 		if err != nil {
-			uc.ctx.Logger().Error(err.Error())
+			uc.ctx.Logger().ErrorContext(ctx, err.Error())
 			return nil, ErrInternal
 		}
 		if user == nil {
-			uc.ctx.Logger().Error("Couldn't find player by id", "player_id", placement.Id)
+			uc.ctx.Logger().ErrorContext(ctx, "Couldn't find player by id", "player_id", placement.Id)
 			return nil, ErrPlayerNotFound
 		}
 		playerStats[i] = models.PlayerStats{
@@ -101,13 +101,13 @@ func (uc *MatchUseCase) CreateMatchResult(ctx context.Context, req *props.Create
 func (uc *MatchUseCase) GetMatchResultById(ctx context.Context, req *props.GetMatchResultByIdReq) (resp *props.GetMatchResultByIdResp, err error) {
 	match, err := uc.ctx.Connection().MatchRepository().GetById(ctx, req.Id)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		return nil, ErrInternal
 	}
 
 	placements, err := uc.ctx.Connection().MatchRepository().GetPlayerPlacementsByMatchId(ctx, req.Id)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		return nil, ErrInternal
 	}
 
@@ -132,7 +132,7 @@ func (uc *MatchUseCase) GetMatchResultById(ctx context.Context, req *props.GetMa
 func (uc *MatchUseCase) GetAllMatchResults(ctx context.Context, req *props.GetAllMatchResultsReq) (resp *props.GetAllMatchResultsResp, err error) {
 	matches, err := uc.ctx.Connection().MatchRepository().GetAll(ctx)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		return nil, ErrInternal
 	}
 
@@ -145,7 +145,7 @@ func (uc *MatchUseCase) GetAllMatchResults(ctx context.Context, req *props.GetAl
 
 		placements, err := uc.ctx.Connection().MatchRepository().GetPlayerPlacementsByMatchId(ctx, match.Id)
 		if err != nil {
-			uc.ctx.Logger().Error(err.Error())
+			uc.ctx.Logger().ErrorContext(ctx, err.Error())
 			continue
 		}
 
@@ -18,10 +18,10 @@ func NewPlayersUseCase(ctx domain.Context) *PlayerUseCase {
 	}
 }
 
-func (uc *PlayerUseCase) Create(args props.CreatePlayerReq) (resp props.CreatePlayerResp, err error) {
-	id, err := uc.ctx.Connection().UserRepository().Add(context.Background(), &models.User{Name: args.Name, Age: args.Age})
+func (uc *PlayerUseCase) Create(ctx context.Context, args props.CreatePlayerReq) (resp props.CreatePlayerResp, err error) {
+	id, err := uc.ctx.Connection().UserRepository().Add(ctx, &models.User{Name: args.Name, Age: args.Age})
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -32,11 +32,11 @@ func (uc *PlayerUseCase) Create(args props.CreatePlayerReq) (resp props.CreatePl
 	return
 }
 
-func (uc *PlayerUseCase) GetById(args props.GetPlayerByIdReq) (resp props.GetPlayerByIdResp, err error) {
-	user, err := uc.ctx.Connection().UserRepository().GetById(context.Background(), args.Id)
+func (uc *PlayerUseCase) GetById(ctx context.Context, args props.GetPlayerByIdReq) (resp props.GetPlayerByIdResp, err error) {
+	user, err := uc.ctx.Connection().UserRepository().GetById(ctx, args.Id)
 	if err != nil {
 
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
@@ -47,10 +47,10 @@ func (uc *PlayerUseCase) GetById(args props.GetPlayerByIdReq) (resp props.GetPla
 	return
 }
 
-func (uc *PlayerUseCase) GetAll(args props.GetAllPlayersReq) (resp props.GetAllPlayersResp, err error) {
-	players, err := uc.ctx.Connection().UserRepository().GetAll(context.Background())
+func (uc *PlayerUseCase) GetAll(ctx context.Context, args props.GetAllPlayersReq) (resp props.GetAllPlayersResp, err error) {
+	players, err := uc.ctx.Connection().UserRepository().GetAll(ctx)
 	if err != nil {
-		uc.ctx.Logger().Error(err.Error())
+		uc.ctx.Logger().ErrorContext(ctx, err.Error())
 		err = ErrInternal
 		return
 	}
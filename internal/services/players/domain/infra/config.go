@@ -1,9 +1,12 @@
 package infra
 
+import "github.com/MommusWinner/MicroDurak/lib/tracing"
+
 type Config interface {
 	GetJwtPublic() string
 	GetHTTPPort() string
 	GetGRPCPort() string
 	GetDatabaseURL() string
 	GetLogLevel() string
+	GetTracing() tracing.Config
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/alecthomas/kong"
+
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
 	"log"
 )
 
@@ -13,6 +15,8 @@ type Config struct {
 	GRPCPort    string `help:"Port to listen on" env:"GRPC_PORT" default:"9090"`
 	DatabaseURL string `help:"Database connection URL" env:"DATABASE_URL" required:"true"`
 	LogLevel    string `help:"Log level (debug, info, warn, error)" env:"LOG_LEVEL" default:"info"`
+
+	Tracing tracing.Config `embed:""`
 }
 
 func Make() *Config {
@@ -48,3 +52,7 @@ func (s *Config) GetDatabaseURL() string {
 func (s *Config) GetLogLevel() string {
 	return s.LogLevel
 }
+
+func (s *Config) GetTracing() tracing.Config {
+	return s.Tracing
+}
@@ -0,0 +1,310 @@
+// Package amqptest runs a fake AMQP broker for the tests of the packages
+// built on amqppool.
+package amqptest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	frameMethod = 1
+	frameHeader = 2
+	frameBody   = 3
+	frameEnd    = 0xCE
+)
+
+// Broker speaks just enough AMQP 0-9-1 to declare queues and consume
+// from them, and records every declaration it receives.
+type Broker struct {
+	listener net.Listener
+
+	mu        sync.Mutex
+	conns     []net.Conn
+	declared  map[string]int
+	failing   map[string]int
+	confirms  int
+	consumers chan *Consumer
+}
+
+// Consumer is a Basic.Consume the broker answered.
+type Consumer struct {
+	conn    *fakeConn
+	channel uint16
+	tag     string
+}
+
+type fakeConn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+// NewBroker listens on a local port until the test ends.
+func NewBroker(t *testing.T) *Broker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Broker{
+		listener:  listener,
+		declared:  make(map[string]int),
+		failing:   make(map[string]int),
+		consumers: make(chan *Consumer, 8),
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		b.DropConnections()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			go b.serve(&fakeConn{Conn: conn})
+		}
+	}()
+	return b
+}
+
+// URL is the address clients dial.
+func (b *Broker) URL() string {
+	return "amqp://guest:guest@" + b.listener.Addr().String() + "/"
+}
+
+// DropConnections closes every client socket, as a restarting broker would.
+func (b *Broker) DropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		conn.Close()
+	}
+	b.conns = nil
+}
+
+// Declarations counts the declarations of the queue or exchange.
+func (b *Broker) Declarations(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.declared[queue]
+}
+
+// Confirms counts the channels put into confirm mode.
+func (b *Broker) Confirms() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.confirms
+}
+
+// FailDeclare closes the channel on the next times declarations of queue, as
+// a broker refusing the declaration would. The server-named queue is "".
+func (b *Broker) FailDeclare(queue string, times int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failing[queue] += times
+}
+
+// WaitConsumer returns the next consumer the broker answered.
+func (b *Broker) WaitConsumer(t *testing.T) *Consumer {
+	t.Helper()
+	select {
+	case consumer := <-b.consumers:
+		return consumer
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a consumer")
+		return nil
+	}
+}
+
+func (b *Broker) serve(conn *fakeConn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return
+	}
+
+	var start bytes.Buffer
+	start.Write([]byte{0, 9})
+	writeLong(&start, 0) // server properties
+	writeLongstr(&start, "PLAIN")
+	writeLongstr(&start, "en_US")
+	conn.method(0, 10, 10, start.Bytes())
+
+	for {
+		kind, channel, payload, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		if kind != frameMethod {
+			continue
+		}
+
+		args := bytes.NewReader(payload[4:])
+		class := binary.BigEndian.Uint16(payload)
+		method := binary.BigEndian.Uint16(payload[2:])
+
+		switch [2]uint16{class, method} {
+		case [2]uint16{10, 11}: // connection.start-ok
+			var tune bytes.Buffer
+			binary.Write(&tune, binary.BigEndian, uint16(0))
+			binary.Write(&tune, binary.BigEndian, uint32(131072))
+			binary.Write(&tune, binary.BigEndian, uint16(0))
+			conn.method(0, 10, 30, tune.Bytes())
+		case [2]uint16{10, 40}: // connection.open
+			conn.method(0, 10, 41, []byte{0})
+		case [2]uint16{10, 50}: // connection.close
+			conn.method(0, 10, 51, nil)
+			return
+		case [2]uint16{20, 10}: // channel.open
+			conn.method(channel, 20, 11, []byte{0, 0, 0, 0})
+		case [2]uint16{20, 40}: // channel.close
+			conn.method(channel, 20, 41, nil)
+		case [2]uint16{40, 10}: // exchange.declare
+			args.Seek(2, io.SeekCurrent)
+			b.declare(readShortstr(args))
+			conn.method(channel, 40, 11, nil)
+		case [2]uint16{50, 10}: // queue.declare
+			args.Seek(2, io.SeekCurrent)
+			queue := readShortstr(args)
+			if b.fail(queue) {
+				var closing bytes.Buffer
+				binary.Write(&closing, binary.BigEndian, uint16(406))
+				writeShortstr(&closing, "PRECONDITION_FAILED")
+				binary.Write(&closing, binary.BigEndian, uint16(50))
+				binary.Write(&closing, binary.BigEndian, uint16(10))
+				conn.method(channel, 20, 40, closing.Bytes())
+				continue
+			}
+			b.declare(queue)
+
+			var ok bytes.Buffer
+			writeShortstr(&ok, queue)
+			writeLong(&ok, 0)
+			writeLong(&ok, 0)
+			conn.method(channel, 50, 11, ok.Bytes())
+		case [2]uint16{50, 20}: // queue.bind
+			conn.method(channel, 50, 21, nil)
+		case [2]uint16{50, 50}: // queue.unbind
+			conn.method(channel, 50, 51, nil)
+		case [2]uint16{60, 10}: // basic.qos
+			conn.method(channel, 60, 11, nil)
+		case [2]uint16{85, 10}: // confirm.select
+			b.mu.Lock()
+			b.confirms++
+			b.mu.Unlock()
+			conn.method(channel, 85, 11, nil)
+		case [2]uint16{60, 20}: // basic.consume
+			args.Seek(2, io.SeekCurrent)
+			readShortstr(args)
+			tag := readShortstr(args)
+
+			var ok bytes.Buffer
+			writeShortstr(&ok, tag)
+			conn.method(channel, 60, 21, ok.Bytes())
+			b.consumers <- &Consumer{conn: conn, channel: channel, tag: tag}
+		}
+	}
+}
+
+// fail reports whether the declaration of queue is to be refused.
+func (b *Broker) fail(queue string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failing[queue] == 0 {
+		return false
+	}
+	b.failing[queue]--
+	return true
+}
+
+func (b *Broker) declare(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.declared[name]++
+}
+
+// Deliver sends body to the consumer as a basic.deliver with its content,
+// routed with routingKey.
+func (c *Consumer) Deliver(routingKey string, body string) {
+	var deliver bytes.Buffer
+	writeShortstr(&deliver, c.tag)
+	binary.Write(&deliver, binary.BigEndian, uint64(1))
+	deliver.WriteByte(0)
+	writeShortstr(&deliver, "")
+	writeShortstr(&deliver, routingKey)
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.BigEndian, uint16(60))
+	binary.Write(&header, binary.BigEndian, uint16(0))
+	binary.Write(&header, binary.BigEndian, uint64(len(body)))
+	binary.Write(&header, binary.BigEndian, uint16(0))
+
+	c.conn.method(c.channel, 60, 60, deliver.Bytes())
+	c.conn.frame(frameHeader, c.channel, header.Bytes())
+	c.conn.frame(frameBody, c.channel, []byte(body))
+}
+
+func (c *fakeConn) method(channel uint16, class, method uint16, args []byte) {
+	payload := binary.BigEndian.AppendUint16(nil, class)
+	payload = binary.BigEndian.AppendUint16(payload, method)
+	c.frame(frameMethod, channel, append(payload, args...))
+}
+
+func (c *fakeConn) frame(kind byte, channel uint16, payload []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frame := []byte{kind}
+	frame = binary.BigEndian.AppendUint16(frame, channel)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	c.Write(append(frame, frameEnd))
+}
+
+func readFrame(r io.Reader) (kind byte, channel uint16, payload []byte, err error) {
+	header := make([]byte, 7)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	kind = header[0]
+	channel = binary.BigEndian.Uint16(header[1:])
+	payload = make([]byte, binary.BigEndian.Uint32(header[3:])+1)
+	_, err = io.ReadFull(r, payload)
+	return kind, channel, payload[:len(payload)-1], err
+}
+
+func readShortstr(r io.Reader) string {
+	var size [1]byte
+	io.ReadFull(r, size[:])
+	value := make([]byte, size[0])
+	io.ReadFull(r, value)
+	return string(value)
+}
+
+func writeShortstr(w *bytes.Buffer, value string) {
+	w.WriteByte(byte(len(value)))
+	w.WriteString(value)
+}
+
+func writeLongstr(w *bytes.Buffer, value string) {
+	writeLong(w, uint32(len(value)))
+	w.WriteString(value)
+}
+
+func writeLong(w *bytes.Buffer, value uint32) {
+	binary.Write(w, binary.BigEndian, value)
+}
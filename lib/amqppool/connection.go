@@ -0,0 +1,253 @@
+package amqppool
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+var (
+	ErrClosed = errors.New("amqp connection is closed")
+)
+
+// Declarer declares exchanges, queues and bindings on a fresh channel. It is
+// run on connect and again after every reconnect.
+type Declarer func(ch *amqp.Channel) error
+
+// ConsumeSetup starts a consumer on the given channel. It is rerun on a new
+// channel whenever the previous delivery stream ends.
+type ConsumeSetup func(ch *amqp.Channel) (<-chan amqp.Delivery, error)
+
+// Connection supervises an AMQP connection: it reconnects with exponential
+// backoff when the broker goes away, re-declares the registered topology and
+// lets consumers resubscribe.
+type Connection struct {
+	name string
+	url  string
+
+	mu          sync.RWMutex
+	conn        *amqp.Connection
+	declarers   []Declarer
+	reconnected chan struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Dial connects to url and starts supervising the connection. The name labels
+// the reconnect metrics.
+func Dial(name string, url string) (*Connection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Connection{
+		name:        name,
+		url:         url,
+		conn:        conn,
+		reconnected: make(chan struct{}),
+		closed:      make(chan struct{}),
+	}
+	ConnectionUp.WithLabelValues(name).Set(1)
+
+	go c.supervise(conn)
+
+	return c, nil
+}
+
+// Channel opens a channel on the current connection.
+func (c *Connection) Channel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	select {
+	case <-c.closed:
+		return nil, ErrClosed
+	default:
+	}
+
+	return conn.Channel()
+}
+
+// DeclareTopology runs declare now and remembers it for reconnects.
+func (c *Connection) DeclareTopology(declare Declarer) error {
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := declare(ch); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.declarers = append(c.declarers, declare)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Consume runs setup and passes every delivery to handle, starting over on a
+// new channel after the delivery stream is closed. It blocks until the
+// connection is closed.
+func (c *Connection) Consume(setup ConsumeSetup, handle func(amqp.Delivery)) error {
+	backoff := minBackoff
+
+	for {
+		c.mu.RLock()
+		reconnected := c.reconnected
+		c.mu.RUnlock()
+
+		ch, err := c.Channel()
+		if errors.Is(err, ErrClosed) {
+			return err
+		}
+
+		var msgs <-chan amqp.Delivery
+		if err == nil {
+			msgs, err = setup(ch)
+		}
+
+		if err != nil {
+			slog.Error("AMQP consumer setup failed", "connection", c.name, "error", err)
+		} else {
+			backoff = minBackoff
+			for d := range msgs {
+				handle(d)
+			}
+		}
+
+		if ch != nil && !ch.IsClosed() {
+			ch.Close()
+		}
+
+		// A broken connection resumes on reconnect; a channel-level failure
+		// is retried with backoff on the same connection.
+		select {
+		case <-c.closed:
+			return ErrClosed
+		case <-reconnected:
+		case <-time.After(backoff):
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+// Close stops the supervisor and closes the current connection.
+func (c *Connection) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		close(c.closed)
+		conn := c.conn
+		c.mu.Unlock()
+
+		ConnectionUp.WithLabelValues(c.name).Set(0)
+		err = conn.Close()
+	})
+
+	return err
+}
+
+func (c *Connection) supervise(conn *amqp.Connection) {
+	for {
+		notify := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-c.closed:
+			return
+		case err, ok := <-notify:
+			if !ok {
+				// closed gracefully by Close
+				return
+			}
+			slog.Warn("AMQP connection lost", "connection", c.name, "error", err)
+		}
+
+		ConnectionUp.WithLabelValues(c.name).Set(0)
+
+		conn = c.reconnect()
+		if conn == nil {
+			return
+		}
+	}
+}
+
+func (c *Connection) reconnect() *amqp.Connection {
+	backoff := minBackoff
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-c.closed:
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+
+		conn, err := amqp.Dial(c.url)
+		if err != nil {
+			slog.Warn("AMQP reconnect attempt failed", "connection", c.name, "attempt", attempt, "error", err)
+			continue
+		}
+
+		if err := c.redeclare(conn); err != nil {
+			slog.Error("AMQP topology declaration failed", "connection", c.name, "error", err)
+			conn.Close()
+			continue
+		}
+
+		c.mu.Lock()
+		select {
+		case <-c.closed:
+			c.mu.Unlock()
+			conn.Close()
+			return nil
+		default:
+		}
+		c.conn = conn
+		close(c.reconnected)
+		c.reconnected = make(chan struct{})
+		c.mu.Unlock()
+
+		Reconnects.WithLabelValues(c.name).Inc()
+		ConnectionUp.WithLabelValues(c.name).Set(1)
+		slog.Info("AMQP connection reconnected", "connection", c.name, "attempts", attempt)
+
+		return conn
+	}
+}
+
+func (c *Connection) redeclare(conn *amqp.Connection) error {
+	c.mu.RLock()
+	declarers := append([]Declarer(nil), c.declarers...)
+	c.mu.RUnlock()
+
+	if len(declarers) == 0 {
+		return nil
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, declare := range declarers {
+		if err := declare(ch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
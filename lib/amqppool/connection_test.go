@@ -0,0 +1,89 @@
+package amqppool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/lib/amqppool/amqptest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConnectionReconnectsAndReplaysTopology(t *testing.T) {
+	broker := amqptest.NewBroker(t)
+
+	conn, err := Dial("test", broker.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = conn.DeclareTopology(func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare("jobs-ex", "direct", true, false, false, false, nil); err != nil {
+			return err
+		}
+		_, err := ch.QueueDeclare("jobs", true, false, false, false, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan string, 1)
+	go conn.Consume(
+		func(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+			return ch.Consume("jobs", "", true, false, false, false, nil)
+		},
+		func(d amqp.Delivery) { received <- string(d.Body) },
+	)
+
+	broker.WaitConsumer(t).Deliver("", "before")
+	expectDelivery(t, received, "before")
+
+	broker.DropConnections()
+
+	// the consumer resumes on the new connection once the topology is back
+	consumer := broker.WaitConsumer(t)
+	if broker.Declarations("jobs") != 2 || broker.Declarations("jobs-ex") != 2 {
+		t.Errorf("Topology was not declared again: jobs %d, jobs-ex %d", broker.Declarations("jobs"), broker.Declarations("jobs-ex"))
+	}
+
+	consumer.Deliver("", "after")
+	expectDelivery(t, received, "after")
+}
+
+func expectDelivery(t *testing.T, received <-chan string, body string) {
+	t.Helper()
+	select {
+	case got := <-received:
+		if got != body {
+			t.Errorf("Expected %q, got %q", body, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %q", body)
+	}
+}
+
+func TestConfirmChannelPoolSelectsOnce(t *testing.T) {
+	broker := amqptest.NewBroker(t)
+
+	conn, err := Dial("test", broker.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	pool := NewConfirmChannelPool(conn, 2)
+	defer pool.Close()
+
+	for range 5 {
+		ch, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pool.Return(ch)
+	}
+
+	if confirms := broker.Confirms(); confirms != 2 {
+		t.Errorf("Expected confirm mode once per pooled channel, got %d", confirms)
+	}
+}
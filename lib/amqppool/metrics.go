@@ -0,0 +1,24 @@
+package amqppool
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	Reconnects = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "amqp_reconnects_total",
+			Help: "Total successful reconnects to RabbitMQ",
+		},
+		[]string{"connection"},
+	)
+
+	ConnectionUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "amqp_connection_up",
+			Help: "Whether the connection to RabbitMQ is currently established",
+		},
+		[]string{"connection"},
+	)
+)
@@ -2,28 +2,40 @@ package amqppool
 
 import (
 	amqp "github.com/rabbitmq/amqp091-go"
-	"log"
+	"log/slog"
 	"sync"
 )
 
 type ChannelPool struct {
-	conn     *amqp.Connection
+	conn     *Connection
 	mu       sync.Mutex
 	free     []*amqp.Channel
 	maxConns int
+	// confirm puts every channel into confirm mode when it is opened.
+	confirm bool
 }
 
-func NewChannelPool(conn *amqp.Connection, maxConns int) *ChannelPool {
+func NewChannelPool(conn *Connection, maxConns int) *ChannelPool {
+	return newChannelPool(conn, maxConns, false)
+}
+
+// NewConfirmChannelPool is a pool of channels in confirm mode, so publishers
+// wait for the broker to confirm without a round-trip to enable it first.
+func NewConfirmChannelPool(conn *Connection, maxConns int) *ChannelPool {
+	return newChannelPool(conn, maxConns, true)
+}
+
+func newChannelPool(conn *Connection, maxConns int, confirm bool) *ChannelPool {
 	if maxConns < 1 || maxConns > 100 {
 		panic("Channel pool size should be in range from 1 to 100")
 	}
 
-	pool := &ChannelPool{conn: conn, maxConns: maxConns}
+	pool := &ChannelPool{conn: conn, maxConns: maxConns, confirm: confirm}
 
 	for range maxConns {
-		ch, err := conn.Channel()
+		ch, err := pool.open()
 		if err != nil {
-			log.Printf("Failed to create channel: %v", err)
+			slog.Error("Failed to create channel", "error", err)
 			continue
 		}
 		pool.free = append(pool.free, ch)
@@ -31,20 +43,43 @@ func NewChannelPool(conn *amqp.Connection, maxConns int) *ChannelPool {
 	return pool
 }
 
+// Get returns a pooled channel, skipping channels that were closed by the
+// broker or by a lost connection.
 func (p *ChannelPool) Get() (*amqp.Channel, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if len(p.free) > 0 {
+	for len(p.free) > 0 {
 		ch := p.free[0]
 		p.free = p.free[1:]
-		return ch, nil
+		if !ch.IsClosed() {
+			return ch, nil
+		}
 	}
 
-	return p.conn.Channel()
+	return p.open()
+}
+
+func (p *ChannelPool) open() (*amqp.Channel, error) {
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.confirm {
+		if err := ch.Confirm(false); err != nil {
+			ch.Close()
+			return nil, err
+		}
+	}
+	return ch, nil
 }
 
 func (p *ChannelPool) Return(ch *amqp.Channel) {
+	if ch == nil || ch.IsClosed() {
+		return
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -2,14 +2,63 @@ package jwt
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
-func AuthMiddleware(jwtKey string) echo.MiddlewareFunc {
+const (
+	// TokenSubprotocolPrefix marks the Sec-WebSocket-Protocol entry carrying
+	// the token, e.g. "bearer.<jwt>". Browsers cannot set headers on
+	// WebSocket upgrades, so they offer the token as a subprotocol next to
+	// the protocol the server selects.
+	TokenSubprotocolPrefix = "bearer."
+	// TicketQueryParam carries a short-lived ticket instead of a token.
+	TicketQueryParam = "ticket"
+
+	bearerPrefix = "bearer "
+)
+
+// TicketValidator redeems a ticket and returns the id of the player it was
+// issued to. It gets the request context so it can check the ticket scope
+// against route parameters.
+type TicketValidator func(c echo.Context, ticket string) (playerId string, err error)
+
+type options struct {
+	tickets TicketValidator
+}
+
+type Option func(*options)
+
+// WithTickets accepts tickets passed in the TicketQueryParam query parameter.
+func WithTickets(validator TicketValidator) Option {
+	return func(o *options) {
+		o.tickets = validator
+	}
+}
+
+// AuthMiddleware takes the token from the Authorization header, with or
+// without the "Bearer" prefix, or from the Sec-WebSocket-Protocol header.
+// With WithTickets, a ticket query parameter is accepted instead.
+func AuthMiddleware(jwtKey string, opts ...Option) echo.MiddlewareFunc {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			token := c.Request().Header.Get("Authorization")
+			if ticket := c.QueryParam(TicketQueryParam); ticket != "" && o.tickets != nil {
+				playerId, err := o.tickets(c, ticket)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid ticket")
+				}
+
+				c.Set("playerId", playerId)
+				return next(c)
+			}
+
+			token := tokenFromRequest(c.Request())
 			if token == "" {
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing token")
 			}
@@ -29,3 +78,23 @@ func AuthMiddleware(jwtKey string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(header[len(bearerPrefix):])
+		}
+		return header
+	}
+
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocol = strings.TrimSpace(protocol)
+			if strings.HasPrefix(protocol, TokenSubprotocolPrefix) {
+				return protocol[len(TokenSubprotocolPrefix):]
+			}
+		}
+	}
+
+	return ""
+}
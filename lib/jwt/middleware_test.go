@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	cases := []struct {
+		header   string
+		value    string
+		expected string
+	}{
+		{"Authorization", "token", "token"},
+		{"Authorization", "Bearer token", "token"},
+		{"Authorization", "bearer  token", "token"},
+		{"Sec-WebSocket-Protocol", "durak.v1.json, bearer.token", "token"},
+		{"Sec-WebSocket-Protocol", "durak.v1.json", ""},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(c.header, c.value)
+
+		if token := tokenFromRequest(r); token != c.expected {
+			t.Errorf("%s: %q: expected %q, got %q", c.header, c.value, c.expected, token)
+		}
+	}
+}
+
+func TestAuthMiddlewareTickets(t *testing.T) {
+	validator := func(c echo.Context, ticket string) (string, error) {
+		if ticket != "valid" {
+			return "", errors.New("invalid ticket")
+		}
+		return "player", nil
+	}
+	handler := AuthMiddleware("", WithTickets(validator))(func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get("playerId").(string))
+	})
+
+	e := echo.New()
+	for ticket, expected := range map[string]int{"valid": http.StatusOK, "used": http.StatusUnauthorized} {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest("GET", "/?ticket="+ticket, nil), rec)
+
+		err := handler(c)
+		if he, ok := err.(*echo.HTTPError); ok {
+			rec.Code = he.Code
+		}
+		if rec.Code != expected {
+			t.Errorf("Ticket %q: expected %d, got %d", ticket, expected, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware assigns every request an id, taken from the X-Request-ID header
+// when the caller sent one, stores it in the request context and logs the
+// request once it is served.
+func Middleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			requestId := r.Header.Get(echo.HeaderXRequestID)
+			if requestId == "" {
+				requestId = uuid.NewString()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestId)
+			c.SetRequest(r.WithContext(WithRequestId(r.Context(), requestId)))
+
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			if r.URL.Path != "/metrics" {
+				logger.InfoContext(c.Request().Context(), "HTTP request",
+					"method", r.Method,
+					"route", c.Path(),
+					"uri", r.RequestURI,
+					"status", c.Response().Status,
+					"duration", time.Since(start),
+				)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	return record
+}
+
+func TestLoggerEnrichesFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, "test", slog.LevelInfo)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestId(context.Background(), "req-1"), spanContext)
+
+	logger.InfoContext(ctx, "hello")
+	record := decode(t, &buf)
+	if record["service"] != "test" || record["request_id"] != "req-1" || record["trace_id"] != spanContext.TraceID().String() {
+		t.Errorf("Unexpected record %v", record)
+	}
+
+	logger.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("Debug record should be dropped, got %s", buf.String())
+	}
+}
+
+func TestLoggerRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, "test", slog.LevelInfo)
+
+	logger.Info("Send email to john.doe@example.com",
+		"password", "hunter2",
+		"header", "Bearer abc.def",
+		"uri", "/ws?ticket=abcdef&x=1",
+		"error", errors.New("no user jane@example.org"),
+	)
+	record := decode(t, &buf)
+
+	expected := map[string]string{
+		"msg":      "Send email to j***@example.com",
+		"password": redacted,
+		"header":   "Bearer " + redacted,
+		"uri":      "/ws?ticket=" + redacted + "&x=1",
+		"error":    "no user j***@example.org",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, record[key])
+		}
+	}
+}
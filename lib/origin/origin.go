@@ -0,0 +1,53 @@
+package origin
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Checker builds a CheckOrigin function for websocket.Upgrader. Allowed
+// origins are "scheme://host[:port]", "scheme://*.domain" for subdomains, or
+// "*" for any origin. Requests without an Origin header come from non-browser
+// clients and are accepted; with an empty list only same-origin requests are.
+func Checker(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		requestOrigin := r.Header.Get("Origin")
+		if requestOrigin == "" {
+			return true
+		}
+
+		u, err := url.Parse(requestOrigin)
+		if err != nil {
+			return false
+		}
+
+		if len(allowed) == 0 {
+			return strings.EqualFold(u.Host, r.Host)
+		}
+
+		for _, pattern := range allowed {
+			if matches(pattern, u) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matches(pattern string, u *url.URL) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "*" {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.EqualFold(scheme, u.Scheme) {
+		return false
+	}
+
+	if suffix, ok := strings.CutPrefix(host, "*."); ok {
+		return len(u.Host) > len(suffix)+1 && strings.HasSuffix(strings.ToLower(u.Host), "."+strings.ToLower(suffix))
+	}
+	return strings.EqualFold(host, u.Host)
+}
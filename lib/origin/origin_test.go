@@ -0,0 +1,36 @@
+package origin
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChecker(t *testing.T) {
+	cases := []struct {
+		allowed []string
+		origin  string
+		ok      bool
+	}{
+		{nil, "", true},
+		{nil, "http://example.com", true},
+		{nil, "http://evil.com", false},
+		{[]string{"*"}, "http://evil.com", true},
+		{[]string{"https://durak.io"}, "https://durak.io", true},
+		{[]string{"https://durak.io"}, "http://durak.io", false},
+		{[]string{"https://durak.io"}, "https://durak.io:8443", false},
+		{[]string{"https://*.durak.io"}, "https://play.durak.io", true},
+		{[]string{"https://*.durak.io"}, "https://durak.io", false},
+		{[]string{"https://*.durak.io"}, "https://evildurak.io", false},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "http://example.com/ws", nil)
+		if c.origin != "" {
+			r.Header.Set("Origin", c.origin)
+		}
+
+		if ok := Checker(c.allowed)(r); ok != c.ok {
+			t.Errorf("allowed %v, origin %q: expected %v, got %v", c.allowed, c.origin, c.ok, ok)
+		}
+	}
+}
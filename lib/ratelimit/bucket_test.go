@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := NewTokenBucket(2, time.Second)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	if !bucket.Allow() || !bucket.Allow() {
+		t.Fatal("Burst should be allowed")
+	}
+	if bucket.Allow() {
+		t.Fatal("Bucket should be empty after the burst")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if bucket.Allow() {
+		t.Fatal("Half a token should not be enough")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if !bucket.Allow() {
+		t.Fatal("Token should be refilled after the interval")
+	}
+
+	now = now.Add(time.Hour)
+	for range 2 {
+		if !bucket.Allow() {
+			t.Fatal("Refill should be capped at the burst")
+		}
+	}
+	if bucket.Allow() {
+		t.Fatal("Refill should not exceed the burst")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	guard := NewGuard(1, time.Hour, 2)
+
+	if guard.Check() != Allow {
+		t.Fatal("First message should be allowed")
+	}
+	if guard.Check() != Reject {
+		t.Fatal("Message over the limit should be rejected")
+	}
+	if guard.Check() != Disconnect {
+		t.Fatal("Repeated violations should disconnect")
+	}
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import "time"
+
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	// Reject drops the message and tells the client about the violation.
+	Reject
+	// Disconnect closes the connection after repeated violations.
+	Disconnect
+)
+
+// Guard rate limits the inbound messages of a single connection and
+// disconnects clients that keep exceeding the limit.
+type Guard struct {
+	bucket        *TokenBucket
+	maxViolations int
+	violations    int
+}
+
+// NewGuard allows bursts of burst messages, refilled one per interval. A
+// maxViolations of zero never disconnects.
+func NewGuard(burst int, interval time.Duration, maxViolations int) *Guard {
+	return &Guard{
+		bucket:        NewTokenBucket(burst, interval),
+		maxViolations: maxViolations,
+	}
+}
+
+// Check is called for every inbound message.
+func (g *Guard) Check() Verdict {
+	if g.bucket.Allow() {
+		return Allow
+	}
+
+	g.violations++
+	if g.maxViolations > 0 && g.violations >= g.maxViolations {
+		return Disconnect
+	}
+	return Reject
+}
@@ -0,0 +1,91 @@
+package ticket
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL is how long a ticket stays valid unless configured otherwise.
+const DefaultTTL = 30 * time.Second
+
+// ScopeMatchmaker scopes a ticket to the matchmaker WebSocket.
+const ScopeMatchmaker = "matchmaker"
+
+const keyPrefix = "ticket:"
+
+// ErrInvalidTicket is returned for unknown, expired, already used or
+// out-of-scope tickets.
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+// GameScope scopes a ticket to the game-manager WebSocket of one game.
+func GameScope(gameId string) string {
+	return "game:" + gameId
+}
+
+type entry struct {
+	PlayerId string `json:"player_id"`
+	Scope    string `json:"scope"`
+}
+
+// Store keeps single-use tickets in redis. Tickets let clients open a
+// WebSocket without putting their long-lived token into the URL.
+type Store struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewStore(client *redis.Client, ttl time.Duration) *Store {
+	return &Store{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// TTL is how long an issued ticket stays valid.
+func (s *Store) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue creates a ticket for the player that can be used once within the
+// store TTL, and only for the given scope.
+func (s *Store) Issue(ctx context.Context, playerId, scope string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(raw)
+
+	value, err := json.Marshal(entry{PlayerId: playerId, Scope: scope})
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.client.Set(ctx, keyPrefix+ticket, value, s.ttl).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// Consume redeems the ticket and returns the id of the player it was issued
+// to. A ticket is deleted on first use, even when the scope does not match.
+func (s *Store) Consume(ctx context.Context, ticket, scope string) (string, error) {
+	value, err := s.client.GetDel(ctx, keyPrefix+ticket).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrInvalidTicket
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var e entry
+	if err := json.Unmarshal(value, &e); err != nil || e.Scope != scope {
+		return "", ErrInvalidTicket
+	}
+	return e.PlayerId, nil
+}
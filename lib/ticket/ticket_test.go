@@ -0,0 +1,66 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewStore(client, 30*time.Second), server
+}
+
+func TestTicketIsSingleUse(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	ticket, err := store.Issue(ctx, "player", GameScope("game"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playerId, err := store.Consume(ctx, ticket, GameScope("game"))
+	if err != nil || playerId != "player" {
+		t.Fatalf("Expected player, got %q, %v", playerId, err)
+	}
+
+	if _, err := store.Consume(ctx, ticket, GameScope("game")); !errors.Is(err, ErrInvalidTicket) {
+		t.Errorf("Second use should fail, got %v", err)
+	}
+}
+
+func TestTicketScope(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	ticket, _ := store.Issue(ctx, "player", GameScope("game"))
+
+	if _, err := store.Consume(ctx, ticket, ScopeMatchmaker); !errors.Is(err, ErrInvalidTicket) {
+		t.Errorf("Ticket should not be valid for another scope, got %v", err)
+	}
+	if _, err := store.Consume(ctx, ticket, GameScope("game")); !errors.Is(err, ErrInvalidTicket) {
+		t.Errorf("Out-of-scope use should burn the ticket, got %v", err)
+	}
+}
+
+func TestTicketExpires(t *testing.T) {
+	store, server := newTestStore(t)
+	ctx := context.Background()
+
+	ticket, _ := store.Issue(ctx, "player", ScopeMatchmaker)
+	server.FastForward(31 * time.Second)
+
+	if _, err := store.Consume(ctx, ticket, ScopeMatchmaker); !errors.Is(err, ErrInvalidTicket) {
+		t.Errorf("Expired ticket should be rejected, got %v", err)
+	}
+}
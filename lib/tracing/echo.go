@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// Middleware traces HTTP requests of serviceName. WebSocket upgrades are
+// skipped because their span would last as long as the connection.
+func Middleware(serviceName string) echo.MiddlewareFunc {
+	return otelecho.Middleware(serviceName, otelecho.WithSkipper(skipRequest))
+}
+
+func skipRequest(c echo.Context) bool {
+	r := c.Request()
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+
+	path := r.URL.Path
+	return path == "/metrics" || strings.HasPrefix(path, "/swagger/")
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Memory is an in-process Transport for running the game loop without a
+// broker. Updates are handed to subscribers synchronously, in publish order.
+type Memory struct {
+	commands    chan envelope
+	subscribers *registry
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// A command that keeps failing is retried memoryMaxAttempts times, waiting
+// twice as long before every retry, and then dropped like a dead-lettered
+// AMQP message.
+const (
+	memoryMaxAttempts = 5
+	memoryRetryDelay  = 50 * time.Millisecond
+)
+
+// envelope carries the trace context of a command, like the AMQP headers.
+type envelope struct {
+	headers  propagation.MapCarrier
+	message  []byte
+	attempts int
+}
+
+func NewMemory(buffer int) *Memory {
+	return &Memory{
+		commands:    make(chan envelope, buffer),
+		subscribers: newRegistry(),
+		closed:      make(chan struct{}),
+	}
+}
+
+func (t *Memory) SendCommand(ctx context.Context, message []byte) (err error) {
+	headers := propagation.MapCarrier{}
+	ctx, span := startPublish(ctx, gameExchange, gameQueue, headers)
+	defer func() { endSpan(span, err) }()
+
+	select {
+	case t.commands <- envelope{headers: headers, message: message}:
+		return nil
+	case <-t.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ConsumeCommands drops poison messages and retries a command that failed
+// with any other error with backoff, after the commands queued behind it.
+func (t *Memory) ConsumeCommands(handler CommandHandler) error {
+	for {
+		select {
+		case <-t.closed:
+			return ErrClosed
+		case command := <-t.commands:
+			ctx, span := startConsume(gameQueue, command.headers)
+			err := handler(ctx, command.message)
+			endSpan(span, err)
+			command.attempts++
+			switch {
+			case err == nil:
+			case errors.Is(err, ErrPoisonMessage):
+				slog.WarnContext(ctx, "Drop message", "error", err)
+			case command.attempts >= memoryMaxAttempts:
+				slog.ErrorContext(ctx, "Dead-letter message", "attempts", command.attempts, "error", err)
+			default:
+				slog.WarnContext(ctx, "Requeue message", "attempts", command.attempts, "error", err)
+				t.requeue(command)
+			}
+		}
+	}
+}
+
+// requeue puts the command back once its backoff has passed.
+func (t *Memory) requeue(command envelope) {
+	delay := memoryRetryDelay << (command.attempts - 1)
+	time.AfterFunc(delay, func() {
+		select {
+		case t.commands <- command:
+		case <-t.closed:
+		}
+	})
+}
+
+func (t *Memory) SendUpdate(ctx context.Context, gameId string, userId string, message []byte) error {
+	t.subscribers.deliver(RoutingKey(gameId, userId), message)
+	return nil
+}
+
+func (t *Memory) SubscribeUpdates(ctx context.Context, gameId string, userId string, handler Handler) (func(), error) {
+	s := &subscription{
+		routingKey: RoutingKey(gameId, userId),
+		handler:    handler,
+	}
+
+	if err := t.subscribers.add(s, nil); err != nil {
+		return nil, err
+	}
+
+	return func() { t.subscribers.remove(s, nil) }, nil
+}
+
+func (t *Memory) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
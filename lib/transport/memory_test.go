@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryGivesUpOnFailingCommand(t *testing.T) {
+	memory := NewMemory(4)
+	defer memory.Close()
+
+	if err := memory.SendCommand(context.Background(), []byte("failing")); err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts atomic.Int32
+	handled := make(chan string, 1)
+	go memory.ConsumeCommands(func(ctx context.Context, message []byte) error {
+		if string(message) == "failing" {
+			attempts.Add(1)
+			return errors.New("redis is down")
+		}
+		handled <- string(message)
+		return nil
+	})
+
+	// the retries wait 50+100+200+400ms in total, a sixth attempt would
+	// follow 800ms after the fifth
+	deadline := time.Now().Add(3 * time.Second)
+	for attempts.Load() < memoryMaxAttempts && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(time.Second)
+	if got := attempts.Load(); got != memoryMaxAttempts {
+		t.Errorf("Expected %d attempts, got %d", memoryMaxAttempts, got)
+	}
+
+	// the consumer keeps serving other commands
+	if err := memory.SendCommand(context.Background(), []byte("next")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("Consumer stopped after dead-lettering a command")
+	}
+}
@@ -0,0 +1,386 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/MommusWinner/MicroDurak/lib/amqppool"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	gameQueue           = "game"
+	gameExchange        = "gameEx"
+	deadLetterExchange  = "gameDlx"
+	deadLetterQueue     = "game-dead-letter"
+	gameManagerExchange = "game-manager-topic"
+	prefetchCount       = 10
+)
+
+var (
+	ErrPublishNotConfirmed = errors.New("publish was not confirmed by the broker")
+)
+
+// RabbitMQ routes commands through the durable "game" queue and updates
+// through a topic exchange. Every subscribing process consumes one exclusive
+// queue that is bound to the routing keys of its live subscriptions.
+type RabbitMQ struct {
+	conn *amqppool.Connection
+	pool *amqppool.ChannelPool
+
+	subscribers *registry
+	// queueName and consuming are guarded by subscribers.mu.
+	queueName string
+	consuming bool
+}
+
+// NewRabbitMQ declares the topology once; it is declared again by the
+// connection after every reconnect.
+func NewRabbitMQ(conn *amqppool.Connection) (*RabbitMQ, error) {
+	if err := conn.DeclareTopology(declareTopology); err != nil {
+		return nil, err
+	}
+
+	return &RabbitMQ{
+		conn:        conn,
+		pool:        amqppool.NewConfirmChannelPool(conn, 20),
+		subscribers: newRegistry(),
+	}, nil
+}
+
+func declareTopology(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		deadLetterExchange, // name
+		"fanout",           // type
+		true,               // durable
+		false,              // auto-deleted
+		false,              // internal
+		false,              // no-wait
+		nil,                // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare exchange", "error", err)
+		return err
+	}
+	_, err = channel.QueueDeclare(
+		deadLetterQueue, // name
+		true,            // durable
+		false,           // delete when unused
+		false,           // exclusive
+		false,           // no-wait
+		nil,             // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare queue", "error", err)
+		return err
+	}
+	err = channel.QueueBind(deadLetterQueue, "", deadLetterExchange, false, nil)
+	if err != nil {
+		slog.Error("Failed to bind queue", "error", err)
+		return err
+	}
+
+	// Brokers deployed before the queue was durable hold a transient "game"
+	// queue, and declaring it again fails with PRECONDITION_FAILED. Drain and
+	// delete it once before rolling out, see README.
+	_, err = channel.QueueDeclare(
+		gameQueue, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		amqp.Table{"x-dead-letter-exchange": deadLetterExchange}, // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare queue", "error", err)
+		return err
+	}
+	err = channel.ExchangeDeclare(
+		gameExchange, // name
+		"direct",     // type
+		true,         // durable
+		false,        // auto-deleted
+		false,        // internal
+		false,        // no-wait
+		nil,          // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare exchange", "error", err)
+		return err
+	}
+	err = channel.QueueBind(gameQueue, gameQueue, gameExchange, false, nil)
+	if err != nil {
+		slog.Error("Failed to bind queue", "error", err)
+		return err
+	}
+
+	err = channel.ExchangeDeclare(
+		gameManagerExchange, // name
+		"topic",             // type
+		true,                // durable
+		false,               // auto-deleted
+		false,               // internal
+		false,               // no-wait
+		nil,                 // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare exchange", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (t *RabbitMQ) SendCommand(ctx context.Context, message []byte) (err error) {
+	channel, err := t.pool.Get()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get channel from pool", "error", err)
+		return err
+	}
+	defer t.pool.Return(channel)
+
+	headers := amqp.Table{}
+	ctx, span := startPublish(ctx, gameExchange, gameQueue, headerCarrier(headers))
+	defer func() { endSpan(span, err) }()
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx,
+		gameExchange, // exchange
+		gameQueue,    // routing key
+		false,        // mandatory
+		false,        // immediate
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  "text/plain",
+			DeliveryMode: amqp.Persistent,
+			Body:         message,
+		})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish command", "error", err)
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to confirm command", "error", err)
+		return err
+	}
+	if !acked {
+		err = ErrPublishNotConfirmed
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeCommands acks a command once handler returns, dead-letters it on
+// ErrPoisonMessage and requeues it on any other error.
+func (t *RabbitMQ) ConsumeCommands(handler CommandHandler) error {
+	return t.conn.Consume(func(channel *amqp.Channel) (<-chan amqp.Delivery, error) {
+		err := channel.Qos(prefetchCount, 0, false)
+		if err != nil {
+			slog.Error("Failed to set QoS", "error", err)
+			return nil, err
+		}
+
+		return channel.Consume(
+			gameQueue, // queue
+			"",        // consumer
+			false,     // auto-ack
+			false,     // exclusive
+			false,     // no-local
+			false,     // no-wait
+			nil,       // args
+		)
+	}, func(d amqp.Delivery) {
+		ctx, span := startConsume(gameQueue, headerCarrier(d.Headers))
+		err := handler(ctx, d.Body)
+		endSpan(span, err)
+		switch {
+		case err == nil:
+			d.Ack(false)
+		case errors.Is(err, ErrPoisonMessage):
+			slog.WarnContext(ctx, "Dead-letter message", "error", err)
+			d.Nack(false, false)
+		default:
+			slog.WarnContext(ctx, "Requeue message", "error", err)
+			d.Nack(false, true)
+		}
+	})
+}
+
+func (t *RabbitMQ) SendUpdate(ctx context.Context, gameId string, userId string, message []byte) (err error) {
+	channel, err := t.pool.Get()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get channel from pool", "error", err)
+		return err
+	}
+	defer t.pool.Return(channel)
+
+	headers := amqp.Table{}
+	ctx, span := startPublish(ctx, gameManagerExchange, RoutingKey(gameId, userId), headerCarrier(headers))
+	defer func() { endSpan(span, err) }()
+
+	err = channel.PublishWithContext(ctx,
+		gameManagerExchange,        // exchange
+		RoutingKey(gameId, userId), // routing key
+		false,                      // mandatory
+		false,                      // immediate
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  "text/plain",
+			DeliveryMode: amqp.Persistent,
+			Body:         message,
+		})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish update", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (t *RabbitMQ) SubscribeUpdates(ctx context.Context, gameId string, userId string, handler Handler) (func(), error) {
+	if err := t.startUpdatesConsumer(); err != nil {
+		return nil, err
+	}
+
+	s := &subscription{
+		routingKey: RoutingKey(gameId, userId),
+		handler:    handler,
+	}
+
+	err := t.subscribers.add(s, func() error {
+		return t.bind(s.routingKey, true)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to bind queue", "error", err)
+		return nil, err
+	}
+
+	return func() {
+		t.subscribers.remove(s, func() {
+			if err := t.bind(s.routingKey, false); err != nil {
+				slog.ErrorContext(ctx, "Failed to unbind queue", "error", err)
+			}
+		})
+	}, nil
+}
+
+func (t *RabbitMQ) Close() error {
+	t.pool.Close()
+	return nil
+}
+
+// startUpdatesConsumer lazily starts consuming the exclusive update queue of
+// this process, so that command consumers never declare one. The first setup
+// runs here, a failed one is tried again by the next subscription. Once it
+// succeeded, the connection sets the queue up again after every reconnect.
+func (t *RabbitMQ) startUpdatesConsumer() error {
+	t.subscribers.mu.Lock()
+	defer t.subscribers.mu.Unlock()
+
+	if t.consuming {
+		return nil
+	}
+
+	channel, err := t.conn.Channel()
+	if err != nil {
+		slog.Error("Failed to open channel", "error", err)
+		return err
+	}
+	msgs, err := t.declareUpdatesQueue(channel)
+	if err != nil {
+		if !channel.IsClosed() {
+			channel.Close()
+		}
+		return err
+	}
+	t.consuming = true
+
+	go func() {
+		for d := range msgs {
+			t.dispatch(d)
+		}
+		if !channel.IsClosed() {
+			channel.Close()
+		}
+		t.conn.Consume(t.consumeUpdatesQueue, t.dispatch)
+	}()
+	return nil
+}
+
+// consumeUpdatesQueue sets the update queue up again on a new channel.
+func (t *RabbitMQ) consumeUpdatesQueue(channel *amqp.Channel) (<-chan amqp.Delivery, error) {
+	t.subscribers.mu.Lock()
+	defer t.subscribers.mu.Unlock()
+
+	return t.declareUpdatesQueue(channel)
+}
+
+// declareUpdatesQueue declares a fresh exclusive queue and binds it to the
+// routing keys of live subscriptions. It is called with subscribers.mu held.
+func (t *RabbitMQ) declareUpdatesQueue(channel *amqp.Channel) (<-chan amqp.Delivery, error) {
+	queue, err := channel.QueueDeclare(
+		"",    // name
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		slog.Error("Failed to declare queue", "error", err)
+		return nil, err
+	}
+	t.queueName = queue.Name
+
+	for routingKey := range t.subscribers.subscribers {
+		err := channel.QueueBind(t.queueName, routingKey, gameManagerExchange, false, nil)
+		if err != nil {
+			slog.Error("Failed to bind queue", "error", err)
+			return nil, err
+		}
+	}
+
+	msgs, err := channel.Consume(
+		t.queueName, // queue
+		"",          // consumer
+		false,       // auto-ack
+		true,        // exclusive
+		false,       // no-local
+		false,       // no-wait
+		nil,         // args
+	)
+	if err != nil {
+		slog.Error("Failed to consume queue", "error", err)
+		return nil, err
+	}
+
+	return msgs, nil
+}
+
+func (t *RabbitMQ) dispatch(d amqp.Delivery) {
+	_, span := startConsume(gameManagerExchange, headerCarrier(d.Headers))
+	defer span.End()
+
+	if t.subscribers.deliver(d.RoutingKey, d.Body) {
+		d.Ack(false)
+	} else {
+		d.Nack(false, false)
+	}
+}
+
+// bind is called with subscribers.mu held.
+func (t *RabbitMQ) bind(routingKey string, bind bool) error {
+	channel, err := t.pool.Get()
+	if err != nil {
+		return err
+	}
+	defer t.pool.Return(channel)
+
+	if bind {
+		return channel.QueueBind(t.queueName, routingKey, gameManagerExchange, false, nil)
+	}
+	return channel.QueueUnbind(t.queueName, routingKey, gameManagerExchange, nil)
+}
@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MommusWinner/MicroDurak/lib/amqppool"
+	"github.com/MommusWinner/MicroDurak/lib/amqppool/amqptest"
+)
+
+func TestRabbitMQRetriesFailedUpdatesSetup(t *testing.T) {
+	broker := amqptest.NewBroker(t)
+
+	conn, err := amqppool.Dial("test", broker.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	rabbit, err := NewRabbitMQ(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rabbit.Close()
+
+	// the broker refuses the first update queue, e.g. during a restart
+	broker.FailDeclare("", 1)
+	ctx := context.Background()
+	if _, err := rabbit.SubscribeUpdates(ctx, "game", "first", func([]byte) error { return nil }); err == nil {
+		t.Fatal("Expected the first subscription to fail")
+	}
+
+	received := make(chan string, 1)
+	unsubscribe, err := rabbit.SubscribeUpdates(ctx, "game", "second", func(message []byte) error {
+		received <- string(message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("The next subscription did not set the queue up again: %v", err)
+	}
+	defer unsubscribe()
+
+	broker.WaitConsumer(t).Deliver(RoutingKey("game", "second"), "update")
+	select {
+	case message := <-received:
+		if message != "update" {
+			t.Errorf("Expected the update, got %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the update")
+	}
+}
@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCommandContinuesTrace(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "command")
+	defer parent.End()
+
+	memory := NewMemory(1)
+	if err := memory.SendCommand(ctx, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan trace.SpanContext, 1)
+	go memory.ConsumeCommands(func(ctx context.Context, message []byte) error {
+		received <- trace.SpanContextFromContext(ctx)
+		memory.Close()
+		return nil
+	})
+
+	spanContext := <-received
+	if spanContext.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Expected trace %s, got %s", parent.SpanContext().TraceID(), spanContext.TraceID())
+	}
+	if spanContext.SpanID() == parent.SpanContext().SpanID() {
+		t.Error("Consumer should start its own span")
+	}
+}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrPoisonMessage marks commands that can never be handled. They are
+	// dead-lettered instead of being redelivered.
+	ErrPoisonMessage = errors.New("poison message")
+	ErrClosed        = errors.New("transport is closed")
+)
+
+type Handler func(message []byte) error
+
+// CommandHandler receives the context of the consumer span of a command, so
+// that the updates it sends continue the trace of the command.
+type CommandHandler func(ctx context.Context, message []byte) error
+
+// Transport carries player commands from game-manager to the game service and
+// per-player game updates back.
+type Transport interface {
+	SendCommand(ctx context.Context, message []byte) error
+	// ConsumeCommands passes every command to handler and blocks until the
+	// transport is closed.
+	ConsumeCommands(handler CommandHandler) error
+
+	SendUpdate(ctx context.Context, gameId string, userId string, message []byte) error
+	// SubscribeUpdates delivers updates for the player of the game until the
+	// returned unsubscribe function is called.
+	SubscribeUpdates(ctx context.Context, gameId string, userId string, handler Handler) (unsubscribe func(), err error)
+
+	Close() error
+}
+
+// RoutingKey addresses the updates for a single player of a single game.
+func RoutingKey(gameId string, userId string) string {
+	return gameId + "." + userId
+}
+
+type subscription struct {
+	routingKey string
+	handler    Handler
+}
+
+// registry tracks update subscriptions by routing key.
+type registry struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*subscription]struct{}
+}
+
+func newRegistry() *registry {
+	return &registry{subscribers: make(map[string]map[*subscription]struct{})}
+}
+
+// add registers s and calls onFirst while holding the lock when s is the
+// first subscription for its routing key.
+func (r *registry) add(s *subscription, onFirst func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.subscribers[s.routingKey]) == 0 {
+		if onFirst != nil {
+			if err := onFirst(); err != nil {
+				return err
+			}
+		}
+		r.subscribers[s.routingKey] = make(map[*subscription]struct{})
+	}
+	r.subscribers[s.routingKey][s] = struct{}{}
+
+	return nil
+}
+
+// remove unregisters s and calls onLast while holding the lock when s was
+// the last subscription for its routing key.
+func (r *registry) remove(s *subscription, onLast func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscribers, ok := r.subscribers[s.routingKey]
+	if !ok {
+		return
+	}
+	if _, ok := subscribers[s]; !ok {
+		return
+	}
+	delete(subscribers, s)
+	if len(subscribers) > 0 {
+		return
+	}
+	delete(r.subscribers, s.routingKey)
+
+	if onLast != nil {
+		onLast()
+	}
+}
+
+func (r *registry) handlers(routingKey string) []Handler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	handlers := make([]Handler, 0, len(r.subscribers[routingKey]))
+	for s := range r.subscribers[routingKey] {
+		handlers = append(handlers, s.handler)
+	}
+
+	return handlers
+}
+
+// deliver passes message to every subscriber of routingKey and reports
+// whether at least one of them accepted it.
+func (r *registry) deliver(routingKey string, message []byte) bool {
+	delivered := false
+	for _, handler := range r.handlers(routingKey) {
+		if err := handler(message); err != nil {
+			continue
+		}
+		delivered = true
+	}
+
+	return delivered
+}
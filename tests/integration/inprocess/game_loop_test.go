@@ -0,0 +1,308 @@
+package inprocess
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/MommusWinner/MicroDurak/internal/contracts/game/v1"
+	managerCore "github.com/MommusWinner/MicroDurak/internal/services/game-manager/core"
+	managerCases "github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/cases"
+	managerProps "github.com/MommusWinner/MicroDurak/internal/services/game-manager/domain/props"
+	gameConfig "github.com/MommusWinner/MicroDurak/internal/services/game/config"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/controller"
+	"github.com/MommusWinner/MicroDurak/internal/services/game/core"
+	gameGrpc "github.com/MommusWinner/MicroDurak/internal/services/game/grpc"
+	matchmakerConnection "github.com/MommusWinner/MicroDurak/internal/services/matchmaker/connection"
+	matchmakerCore "github.com/MommusWinner/MicroDurak/internal/services/matchmaker/core"
+	matchmakerCases "github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/cases"
+	"github.com/MommusWinner/MicroDurak/internal/services/matchmaker/domain/types"
+	matchmakerConfig "github.com/MommusWinner/MicroDurak/internal/services/matchmaker/infra/config"
+	"github.com/MommusWinner/MicroDurak/lib/tracing"
+	"github.com/MommusWinner/MicroDurak/lib/transport"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+const waitTimeout = 10 * time.Second
+
+// gameClient calls the game gRPC server directly, without a network hop.
+type gameClient struct {
+	server *gameGrpc.GameServer
+}
+
+func (c gameClient) CreateGame(ctx context.Context, in *pb.CreateGameRequest, opts ...grpc.CallOption) (*pb.CreateGameResponse, error) {
+	return c.server.CreateGame(ctx, in)
+}
+
+type managerConfig struct{}
+
+func (managerConfig) GetJWTPublic() string              { return "" }
+func (managerConfig) GetRabbitmqURL() string            { return "" }
+func (managerConfig) GetRedisURL() string               { return "" }
+func (managerConfig) GetPort() string                   { return "" }
+func (managerConfig) GetPodName() string                { return "inprocess" }
+func (managerConfig) GetNamespace() string              { return "inprocess" }
+func (managerConfig) GetLogLevel() string               { return "info" }
+func (managerConfig) GetTracing() tracing.Config        { return tracing.Config{} }
+func (managerConfig) GetPingInterval() time.Duration    { return time.Minute }
+func (managerConfig) GetPongWait() time.Duration        { return time.Minute }
+func (managerConfig) GetWriteWait() time.Duration       { return time.Second }
+func (managerConfig) GetSendBufferSize() int            { return 64 }
+func (managerConfig) GetSessionPolicy() string          { return "takeover" }
+func (managerConfig) GetAllowedOrigins() []string       { return nil }
+func (managerConfig) GetMaxFrameSize() int              { return 4096 }
+func (managerConfig) GetCommandBurst() int              { return 10 }
+func (managerConfig) GetCommandInterval() time.Duration { return 100 * time.Millisecond }
+func (managerConfig) GetMaxViolations() int             { return 5 }
+func (managerConfig) GetChatHistorySize() int           { return 50 }
+func (managerConfig) GetChatMaxLength() int             { return 200 }
+func (managerConfig) GetChatBurst() int                 { return 5 }
+func (managerConfig) GetChatInterval() time.Duration    { return time.Second }
+func (managerConfig) GetChatSpectators() bool           { return false }
+func (managerConfig) GetChatEmotes() []string           { return []string{"wave"} }
+func (managerConfig) GetChatBannedWords() []string      { return nil }
+
+type managerMetrics struct{}
+
+func (managerMetrics) IncPlayersConnected(podName, namespace string)                             {}
+func (managerMetrics) DecPlayersConnected(podName, namespace string)                             {}
+func (managerMetrics) IncViolations(podName, namespace, reason string)                           {}
+func (managerMetrics) IncAbuseDisconnects(podName, namespace string)                             {}
+func (managerMetrics) IncActiveGames(podName, namespace string)                                  {}
+func (managerMetrics) DecActiveGames(podName, namespace string)                                  {}
+func (managerMetrics) IncMessagesReceived(podName, namespace, messageType string)                {}
+func (managerMetrics) IncMessagesSent(podName, namespace, messageType string)                    {}
+func (managerMetrics) IncWebSocketErrors(podName, namespace, kind string)                        {}
+func (managerMetrics) ObservePublishDuration(podName, namespace string, d time.Duration)         {}
+func (managerMetrics) ObserveCommandDuration(podName, namespace, action string, d time.Duration) {}
+
+// fakeWebSocket feeds commands into the game-manager and collects the packs
+// it writes back.
+type fakeWebSocket struct {
+	in        chan []byte
+	out       chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newFakeWebSocket() *fakeWebSocket {
+	return &fakeWebSocket{
+		in:     make(chan []byte),
+		out:    make(chan []byte, 64),
+		closed: make(chan struct{}),
+	}
+}
+
+func (w *fakeWebSocket) ReadMessage() ([]byte, error) {
+	select {
+	case message := <-w.in:
+		return message, nil
+	case <-w.closed:
+		return nil, io.EOF
+	}
+}
+
+func (w *fakeWebSocket) WriteMessage(message []byte) error {
+	w.out <- message
+	return nil
+}
+
+func (w *fakeWebSocket) Ping() error {
+	return nil
+}
+
+func (w *fakeWebSocket) CloseWithCode(code int, reason string) error {
+	return w.Close()
+}
+
+func (w *fakeWebSocket) Close() error {
+	w.closeOnce.Do(func() { close(w.closed) })
+	return nil
+}
+
+func (w *fakeWebSocket) send(t *testing.T, command any) {
+	t.Helper()
+	message, err := json.Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case w.in <- message:
+	case <-time.After(waitTimeout):
+		t.Fatal("Timed out sending command")
+	}
+}
+
+// waitForEvent reads packs until one carries the event and returns the game
+// state of that pack.
+func (w *fakeWebSocket) waitForEvent(t *testing.T, event string) core.GameStateResponse {
+	t.Helper()
+	deadline := time.After(waitTimeout)
+	for {
+		select {
+		case message := <-w.out:
+			var pack struct {
+				Messages  []map[string]any       `json:"messages"`
+				GameState core.GameStateResponse `json:"game_state"`
+			}
+			if err := json.Unmarshal(message, &pack); err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range pack.Messages {
+				if m["event"] == event {
+					return pack.GameState
+				}
+			}
+		case <-deadline:
+			t.Fatalf("Timed out waiting for %s event", event)
+		}
+	}
+}
+
+func findMatch(ctx context.Context, queue chan<- types.MatchChan, playerId string) <-chan string {
+	gameId := make(chan string, 1)
+	responses := make(chan types.MatchResponse, 64)
+	queue <- types.MatchChan{
+		PlayerId:   playerId,
+		Mode:       "classic-2p",
+		Rating:     1000,
+		MinSize:    2,
+		MaxSize:    2,
+		SentTime:   time.Now(),
+		ReturnChan: responses,
+	}
+
+	go func() {
+		for {
+			select {
+			case response := <-responses:
+				if response.Status == types.MatchCreated {
+					gameId <- response.RoomId
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return gameId
+}
+
+// TestGameLoopWithoutOutsideServices wires matchmaker, game and game-manager
+// together over the in-memory transport and miniredis. Unlike the rest of
+// tests/integration it needs no outside services and runs in CI.
+func TestGameLoopWithoutOutsideServices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer redisClient.Close()
+
+	memory := transport.NewMemory(64)
+	defer memory.Close()
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	// game
+	gameConf := &gameConfig.Config{}
+	gameController := controller.NewGameController(gameConf, memory, redisClient, logger)
+	go gameController.ProcessQueues()
+
+	// matchmaker, without a ready-check as READY_CHECK_TIMEOUT is zero
+	queueChan := make(chan types.MatchChan)
+	cancelChan := make(chan types.MatchCancel)
+	matchmaker := matchmakerCases.NewMatchmakerUseCase(
+		matchmakerCore.NewCtx(&matchmakerConfig.Config{LeaderTTL: 5 * time.Second}, logger, matchmakerConnection.NewConnection(redisClient)),
+		queueChan,
+		cancelChan,
+		make(chan types.MatchAccept),
+		gameClient{server: gameGrpc.NewGameServer(&gameController, gameConf)},
+	)
+	go matchmaker.Start(ctx)
+
+	// game-manager
+	managerCtx, err := managerCore.NewCtx(managerConfig{}, logger, memory, redisClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handleMessage := managerCases.NewHandleMessageUseCase(
+		managerCtx,
+		managerMetrics{},
+		managerCases.NewChatUseCase(managerCtx, managerCases.NewWordListFilter(nil)),
+	)
+
+	players := []string{"player1", "player2"}
+	matches := make([]<-chan string, len(players))
+	for i, playerId := range players {
+		matches[i] = findMatch(ctx, queueChan, playerId)
+	}
+
+	var gameId string
+	for i, match := range matches {
+		select {
+		case id := <-match:
+			if gameId != "" && id != gameId {
+				t.Fatalf("Players were matched into different games: %s and %s", gameId, id)
+			}
+			gameId = id
+		case <-time.After(waitTimeout):
+			t.Fatalf("Timed out waiting for a match for %s", players[i])
+		}
+	}
+
+	sockets := make(map[string]*fakeWebSocket, len(players))
+	for _, playerId := range players {
+		ws := newFakeWebSocket()
+		sockets[playerId] = ws
+		go handleMessage.ConnectWebSocket(ctx, managerProps.ConnectWebSocketReq{
+			GameId:    gameId,
+			UserId:    playerId,
+			WebSocket: ws,
+		})
+		defer ws.Close()
+	}
+
+	for _, playerId := range players {
+		sockets[playerId].send(t, core.Command{GameId: gameId, Action: core.ACTION_READY, UserId: playerId})
+	}
+
+	var state core.GameStateResponse
+	for _, playerId := range players {
+		state = sockets[playerId].waitForEvent(t, core.EVENT_START)
+	}
+
+	attacker := state.AttackingId
+	game, err := gameController.LoadGame(context.Background(), gameId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var card core.Card
+	for _, user := range game.Users {
+		if user.Id == attacker {
+			card = user.Cards[0]
+		}
+	}
+
+	sockets[attacker].send(t, core.AttackCommand{
+		Command: core.Command{GameId: gameId, Action: core.ACTION_ATTACK, UserId: attacker},
+		Card:    card,
+	})
+
+	defenderState := sockets[state.DefendingId].waitForEvent(t, core.EVENT_ATTACK)
+	if len(defenderState.TableCards) != 1 || defenderState.TableCards[0].Card != card {
+		t.Errorf("Defender should see the attacking card on the table, got %+v", defenderState.TableCards)
+	}
+
+	// chat stays in the game-manager and reaches the other player
+	sockets[attacker].send(t, map[string]string{"action": "ACTION_CHAT", "text": "your move"})
+	sockets[state.DefendingId].waitForEvent(t, "CHAT")
+}